@@ -1,12 +1,61 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 )
 
 //Settings object for storing settings
 type Settings struct {
-	Auth Authorization
+	Profile       string
+	ConfigFile    string
+	ContentRoot   string
+	NoInput       bool
+	DryRun        bool
+	AnswersFile   string
+	Categories    []string
+	RewriteImages bool
+	ForwardExtra  bool
+	Timezone      *time.Location
+	Defaults      Defaults
+	Auth          Authorization
+	Credentials   CredentialSettings
+	HTTP          HTTPSettings
+	Images        ImageSettings
+}
+
+//Defaults are values used for articles that don't set their own
+type Defaults struct {
+	Author string `yaml:"author"`
+}
+
+//HTTPSettings controls timeouts and retries of requests to the service
+type HTTPSettings struct {
+	Timeout         time.Duration
+	Retries         int
+	RetryDelay      time.Duration
+	MaxRetryDelay   time.Duration
+	IdempotencyKeys bool
+	RateLimit       float64
+}
+
+//ImageSettings controls the processing of images before they are uploaded
+type ImageSettings struct {
+	Process      bool
+	MaxDimension int
+	Quality      int
+	Variants     []ImageVariant
+	CacheFile    string
+}
+
+//ImageVariant is a resized copy of an image uploaded alongside it, fitting within Width and Height
+type ImageVariant struct {
+	Name   string `yaml:"name"`
+	Width  int    `yaml:"width"`
+	Height int    `yaml:"height"`
 }
 
 //Authorization object for keeping credentials
@@ -15,25 +64,142 @@ type Authorization struct {
 	ServiceURL string
 	UserName   string
 	Password   string
+
+	CacheToken     bool
+	TokenCacheFile string
 }
 
-//NewConfiguration creates a new Settings instance
+//NewConfiguration creates a new Settings instance from the defaults and environment variables
 func NewConfiguration() *Settings {
-	serviceURL := getEnvironmentVariable("Article_Service_Url", "http://localhost:9000")
-	authKey := getEnvironmentVariable("Ariticle_Server_AuthKey", "VIrPcAi4Rff0gBwdWklRl3ywMwgC6mZH")
+	configSettings := defaultSettings()
+	configSettings.Profile = getEnvironmentVariable("Article_Profile", configSettings.Profile)
+	configSettings.applyEnvironment()
+	configSettings.Auth.TokenCacheFile = TokenCacheFile(configSettings.Profile)
+	configSettings.Images.CacheFile = ImageCacheFile(configSettings.Profile)
 
-	authSettings := &Authorization{
-		authKey,
-		serviceURL,
-		"",
-		"",
+	return configSettings
+}
+
+//Load creates a new Settings instance from the defaults, the profile in the config file and
+//environment variables, in increasing order of precedence. An empty fileName uses the default
+//config file location and an empty profile uses Article_Profile or the file's default profile.
+func Load(fileName string, profile string) (*Settings, error) {
+	configSettings := defaultSettings()
+
+	explicitFile := fileName != ""
+	if !explicitFile {
+		fileName = DefaultConfigFile()
 	}
 
-	configSettings := &Settings{
-		*authSettings,
+	file, err := readFile(fileName, explicitFile)
+	if err != nil {
+		return nil, err
 	}
 
-	return configSettings
+	if profile == "" && file != nil {
+		profile = getEnvironmentVariable("Article_Profile", file.DefaultProfile)
+	}
+
+	if profile == "" {
+		profile = getEnvironmentVariable("Article_Profile", configSettings.Profile)
+	}
+
+	if file != nil {
+		configSettings.ConfigFile = fileName
+		if err := configSettings.applyProfile(file, profile); err != nil {
+			return nil, err
+		}
+	} else if profile != "default" {
+		// without a config file only the default profile exists, any other is a typo or a missing file
+		return nil, fmt.Errorf("Profile %s not found, there is no config file at %s", profile, fileName)
+	}
+
+	configSettings.Profile = profile
+	configSettings.applyEnvironment()
+	if timezone := os.Getenv("Article_Timezone"); timezone != "" {
+		if err := configSettings.SetTimezone(timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	configSettings.Auth.TokenCacheFile = TokenCacheFile(profile)
+	configSettings.Images.CacheFile = ImageCacheFile(profile)
+
+	return configSettings, nil
+}
+
+//TokenCacheFile returns the location of the cached auth token for a profile
+func TokenCacheFile(profile string) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	return filepath.Join(cacheDir, "article-importer", "tokens", profile+".json")
+}
+
+//ImageCacheFile returns the location of the hashes of the images uploaded for a profile
+func ImageCacheFile(profile string) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	return filepath.Join(cacheDir, "article-importer", "images", profile+".json")
+}
+
+//SetTimezone sets the site timezone from its IANA name, such as America/Chicago
+func (configSettings *Settings) SetTimezone(name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("Invalid timezone %s: %s", name, err.Error())
+	}
+
+	configSettings.Timezone = location
+	return nil
+}
+
+func defaultSettings() *Settings {
+	return &Settings{
+		Profile:  "default",
+		Timezone: time.UTC,
+		Auth: Authorization{
+			ServiceURL: "http://localhost:9000",
+		},
+		Credentials: CredentialSettings{
+			File: DefaultCredentialsFile(),
+		},
+		HTTP: HTTPSettings{
+			Timeout:       30 * time.Second,
+			Retries:       3,
+			RetryDelay:    500 * time.Millisecond,
+			MaxRetryDelay: 10 * time.Second,
+		},
+		Images: ImageSettings{
+			MaxDimension: 2048,
+			Quality:      85,
+			Variants: []ImageVariant{
+				{Name: "thumb", Width: 320, Height: 320},
+				{Name: "medium", Width: 1024, Height: 1024},
+				{Name: "banner", Width: 1920, Height: 1080},
+			},
+		},
+	}
+}
+
+func (configSettings *Settings) applyEnvironment() {
+	configSettings.Auth.ServiceURL = getEnvironmentVariable("Article_Service_Url", configSettings.Auth.ServiceURL)
+	configSettings.Auth.AuthKey = getEnvironmentVariable("Ariticle_Server_AuthKey", configSettings.Auth.AuthKey)
+	configSettings.Auth.AuthKey = getEnvironmentVariable("Article_Server_AuthKey", configSettings.Auth.AuthKey)
+	configSettings.Auth.UserName = getEnvironmentVariable("Article_Username", configSettings.Auth.UserName)
+	configSettings.ContentRoot = getEnvironmentVariable("Article_Location", configSettings.ContentRoot)
+	configSettings.Credentials.File = getEnvironmentVariable("Article_Credentials_File", configSettings.Credentials.File)
+	configSettings.Credentials.KeyFile = getEnvironmentVariable("Article_Credentials_Key_File", configSettings.Credentials.KeyFile)
+	configSettings.Credentials.Passphrase = getEnvironmentVariable("Article_Credentials_Passphrase", configSettings.Credentials.Passphrase)
+
+	if cacheToken, err := strconv.ParseBool(os.Getenv("Article_Token_Cache")); err == nil {
+		configSettings.Auth.CacheToken = cacheToken
+	}
 }
 
 func getEnvironmentVariable(envvar string, defaultValue string) string {
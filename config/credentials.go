@@ -0,0 +1,237 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const credentialsVersion = 1
+
+//ErrWrongSecret is returned when the credential file can't be decrypted with the passphrase or key file
+var ErrWrongSecret = errors.New("Unable to decrypt credentials: wrong passphrase or key file")
+
+//CredentialSettings locates the credential file and the secret protecting it
+type CredentialSettings struct {
+	File       string
+	KeyFile    string
+	Passphrase string
+}
+
+//Credentials are the secrets stored for a profile
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	AuthKey  string `json:"authKey"`
+}
+
+//CredentialStore is an encrypted file of credentials keyed by profile
+type CredentialStore struct {
+	fileName string
+	secret   []byte
+}
+
+//encryptedFile is the on disk layout of the credential file
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+//DefaultCredentialsFile returns the credential file location next to the config file
+func DefaultCredentialsFile() string {
+	return filepath.Join(filepath.Dir(DefaultConfigFile()), "credentials")
+}
+
+//NewCredentialStore creates a CredentialStore for the file, unlocked with secret
+func NewCredentialStore(fileName string, secret []byte) *CredentialStore {
+	return &CredentialStore{
+		fileName: fileName,
+		secret:   secret,
+	}
+}
+
+//Secret returns the key file contents or passphrase protecting the credential file,
+//or nil when neither is configured
+func (credentialSettings CredentialSettings) Secret() ([]byte, error) {
+	if credentialSettings.KeyFile != "" {
+		key, err := ioutil.ReadFile(credentialSettings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read key file: %s", err.Error())
+		}
+
+		if len(key) == 0 {
+			return nil, fmt.Errorf("Key file %s is empty", credentialSettings.KeyFile)
+		}
+
+		return key, nil
+	}
+
+	if credentialSettings.Passphrase != "" {
+		return []byte(credentialSettings.Passphrase), nil
+	}
+
+	return nil, nil
+}
+
+//CredentialFileExists returns true when the credential file has been created
+func CredentialFileExists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+//Get returns the credentials for a profile, or nil when there are none
+func (store *CredentialStore) Get(profile string) (*Credentials, error) {
+	all, err := store.load()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, ok := all[profile]
+	if !ok {
+		return nil, nil
+	}
+
+	return &credentials, nil
+}
+
+//Put saves the credentials for a profile
+func (store *CredentialStore) Put(profile string, credentials Credentials) error {
+	all, err := store.load()
+	if err != nil {
+		return err
+	}
+
+	all[profile] = credentials
+	return store.save(all)
+}
+
+//Delete removes the credentials for a profile, removing the file when it is the last one
+func (store *CredentialStore) Delete(profile string) error {
+	all, err := store.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := all[profile]; !ok {
+		return nil
+	}
+
+	delete(all, profile)
+	if len(all) == 0 {
+		return os.Remove(store.fileName)
+	}
+
+	return store.save(all)
+}
+
+func (store *CredentialStore) load() (map[string]Credentials, error) {
+	all := make(map[string]Credentials)
+
+	data, err := ioutil.ReadFile(store.fileName)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not open credential file: %s", err.Error())
+	}
+
+	file := &encryptedFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling credential file: %s", err.Error())
+	}
+
+	if file.Version != credentialsVersion {
+		return nil, fmt.Errorf("Unsupported credential file version %d", file.Version)
+	}
+
+	gcm, err := newCipher(store.secret, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongSecret
+	}
+
+	if err := json.Unmarshal(plain, &all); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling credentials: %s", err.Error())
+	}
+
+	return all, nil
+}
+
+func (store *CredentialStore) save(all map[string]Credentials) error {
+	plain, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	file := &encryptedFile{
+		Version: credentialsVersion,
+		Salt:    make([]byte, 16),
+	}
+
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := newCipher(store.secret, file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.fileName), 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a failed write can't lose every profile
+	tmpFile := store.fileName + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, store.fileName)
+}
+
+func newCipher(secret []byte, salt []byte) (cipher.AEAD, error) {
+	if len(strings.TrimSpace(string(secret))) == 0 {
+		return nil, errors.New("A passphrase or key file is required for the credential file")
+	}
+
+	key, err := scrypt.Key(secret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+//File is the layout of the config file
+type File struct {
+	DefaultProfile string             `yaml:"defaultProfile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+//Profile holds the settings for one site
+type Profile struct {
+	ServiceURL    string        `yaml:"serviceUrl"`
+	AuthKey       string        `yaml:"authKey"`
+	Username      string        `yaml:"username"`
+	ContentRoot   string        `yaml:"contentRoot"`
+	KeyFile       string        `yaml:"keyFile"`
+	CacheToken    *bool         `yaml:"cacheToken"`
+	Timeout       string        `yaml:"timeout"`
+	Retries       *int          `yaml:"retries"`
+	Categories    []string      `yaml:"categories"`
+	RewriteImages *bool         `yaml:"rewriteImages"`
+	ForwardExtra  *bool         `yaml:"forwardExtra"`
+	Timezone      string        `yaml:"timezone"`
+	Images        *ImageProfile `yaml:"images"`
+	Defaults      Defaults      `yaml:"defaults"`
+}
+
+//ImageProfile overrides the image settings, having one turns processing on unless process is false
+type ImageProfile struct {
+	Process      *bool          `yaml:"process"`
+	MaxDimension int            `yaml:"maxDimension"`
+	Quality      int            `yaml:"quality"`
+	Variants     []ImageVariant `yaml:"variants"`
+}
+
+//DefaultConfigFile returns the config file location under XDG_CONFIG_HOME
+func DefaultConfigFile() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		configDir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configDir, "article-importer", "config.yml")
+}
+
+//readFile reads the config file, a missing file is only an error when it was asked for explicitly
+func readFile(fileName string, required bool) (*File, error) {
+	if fileName == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) && !required {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not open config file: %s", err.Error())
+	}
+
+	file := &File{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Error unmarshaling config file %s: %s", fileName, err.Error())
+	}
+
+	return file, nil
+}
+
+func (configSettings *Settings) applyProfile(file *File, name string) error {
+	profile, ok := file.Profiles[name]
+	if !ok {
+		// a config file without the default profile just means nothing is overridden
+		if name == "default" {
+			return nil
+		}
+
+		return fmt.Errorf("Profile %s not found in %s", name, configSettings.ConfigFile)
+	}
+
+	if profile.ServiceURL != "" {
+		configSettings.Auth.ServiceURL = profile.ServiceURL
+	}
+
+	if profile.AuthKey != "" {
+		configSettings.Auth.AuthKey = profile.AuthKey
+	}
+
+	if profile.Username != "" {
+		configSettings.Auth.UserName = profile.Username
+	}
+
+	if profile.ContentRoot != "" {
+		configSettings.ContentRoot = expandHome(profile.ContentRoot)
+	}
+
+	if profile.KeyFile != "" {
+		configSettings.Credentials.KeyFile = expandHome(profile.KeyFile)
+	}
+
+	if profile.CacheToken != nil {
+		configSettings.Auth.CacheToken = *profile.CacheToken
+	}
+
+	if profile.Timeout != "" {
+		timeout, err := time.ParseDuration(profile.Timeout)
+		if err != nil {
+			return fmt.Errorf("Invalid timeout in profile %s: %s", name, err.Error())
+		}
+
+		configSettings.HTTP.Timeout = timeout
+	}
+
+	if profile.Retries != nil {
+		configSettings.HTTP.Retries = *profile.Retries
+	}
+
+	if profile.RewriteImages != nil {
+		configSettings.RewriteImages = *profile.RewriteImages
+	}
+
+	if profile.ForwardExtra != nil {
+		configSettings.ForwardExtra = *profile.ForwardExtra
+	}
+
+	if profile.Timezone != "" {
+		if err := configSettings.SetTimezone(profile.Timezone); err != nil {
+			return fmt.Errorf("Error in profile %s: %s", name, err.Error())
+		}
+	}
+
+	if profile.Images != nil {
+		configSettings.applyImageProfile(profile.Images)
+	}
+
+	if len(profile.Categories) > 0 {
+		configSettings.Categories = profile.Categories
+	}
+
+	if profile.Defaults.Author != "" {
+		configSettings.Defaults.Author = profile.Defaults.Author
+	}
+
+	return nil
+}
+
+func (configSettings *Settings) applyImageProfile(images *ImageProfile) {
+	configSettings.Images.Process = true
+	if images.Process != nil {
+		configSettings.Images.Process = *images.Process
+	}
+
+	if images.MaxDimension > 0 {
+		configSettings.Images.MaxDimension = images.MaxDimension
+	}
+
+	if images.Quality > 0 {
+		configSettings.Images.Quality = images.Quality
+	}
+
+	if images.Variants != nil {
+		configSettings.Images.Variants = images.Variants
+	}
+}
+
+func expandHome(path string) string {
+	if len(path) < 2 || path[:2] != "~/" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+//Print writes the resolved settings with secrets masked
+func (configSettings *Settings) Print(w io.Writer) error {
+	configFile := configSettings.ConfigFile
+	if configFile == "" {
+		configFile = "(none)"
+	}
+
+	tokenCache := "disabled"
+	if configSettings.Auth.CacheToken {
+		tokenCache = configSettings.Auth.TokenCacheFile
+	}
+
+	timezone := "UTC"
+	if configSettings.Timezone != nil {
+		timezone = configSettings.Timezone.String()
+	}
+
+	values := [][2]string{
+		{"profile", configSettings.Profile},
+		{"config file", configFile},
+		{"service url", configSettings.Auth.ServiceURL},
+		{"auth key", maskSecret(configSettings.Auth.AuthKey)},
+		{"username", configSettings.Auth.UserName},
+		{"password", maskSecret(configSettings.Auth.Password)},
+		{"content root", configSettings.ContentRoot},
+		{"credential file", configSettings.Credentials.File},
+		{"key file", configSettings.Credentials.KeyFile},
+		{"token cache", tokenCache},
+		{"image cache", configSettings.Images.CacheFile},
+		{"timeout", configSettings.HTTP.Timeout.String()},
+		{"retries", fmt.Sprint(configSettings.HTTP.Retries)},
+		{"timezone", timezone},
+		{"categories", strings.Join(configSettings.Categories, ", ")},
+		{"default author", configSettings.Defaults.Author},
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, value := range values {
+		fmt.Fprintf(tw, "%s\t%s\n", value[0], value[1])
+	}
+
+	return tw.Flush()
+}
+
+//maskSecret hides all but the first few characters of a secret
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	if len(secret) <= 8 {
+		return strings.Repeat("*", 8)
+	}
+
+	return secret[:4] + strings.Repeat("*", 8)
+}
@@ -1,8 +1,17 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/evcraddock/article-importer/config"
 	"github.com/evcraddock/article-importer/tasks"
@@ -11,6 +20,11 @@ import (
 
 func main() {
 	configSettings := config.NewConfiguration()
+
+	// cancel in-flight requests on Ctrl-C instead of leaving them hanging
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	app := cli.NewApp()
 	app.Name = "Article Importer"
 	app.Version = "1.0"
@@ -23,23 +37,138 @@ func main() {
 
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
-			Name:        "username",
-			Value:       "",
-			Usage:       "",
-			Destination: &configSettings.Auth.UserName,
+			Name:  "config",
+			Usage: "config file (default $XDG_CONFIG_HOME/article-importer/config.yml)",
+		},
+		cli.StringFlag{
+			Name:  "profile",
+			Usage: "profile in the config file to use",
+		},
+		cli.StringFlag{
+			Name:  "username",
+			Usage: "",
+		},
+		cli.StringFlag{
+			Name:  "password",
+			Usage: "visible to other users on the machine, prefer --password-stdin or --password-file",
+		},
+		cli.BoolFlag{
+			Name:  "password-stdin",
+			Usage: "read the password from stdin",
+		},
+		cli.StringFlag{
+			Name:  "password-file",
+			Usage: "read the password from a file",
+		},
+		cli.StringFlag{
+			Name:  "key-file",
+			Usage: "key file protecting the credential file instead of a passphrase",
+		},
+		cli.StringFlag{
+			Name:  "serviceUrl",
+			Usage: "",
 		},
 		cli.StringFlag{
-			Name:        "password",
-			Value:       "",
-			Usage:       "",
-			Destination: &configSettings.Auth.Password,
+			Name:  "content-root",
+			Usage: "folder containing the article tree",
+		},
+		cli.BoolFlag{
+			Name:  "cache-token",
+			Usage: "reuse the auth token between runs",
+		},
+		cli.BoolFlag{
+			Name:  "no-input",
+			Usage: "never prompt, fail when a required value is missing",
+		},
+		cli.StringFlag{
+			Name:  "answers",
+			Usage: "yaml file of prompt label to value used instead of prompting",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: configSettings.HTTP.Timeout,
+			Usage: "timeout for connecting to the service and for each response, uploads may take longer to send",
+		},
+		cli.IntFlag{
+			Name:  "retries",
+			Value: configSettings.HTTP.Retries,
+			Usage: "number of times to retry a failed idempotent request",
+		},
+		cli.BoolFlag{
+			Name:  "idempotency-keys",
+			Usage: "send Idempotency-Key headers so POST requests can be retried",
+		},
+		cli.Float64Flag{
+			Name:  "rate-limit",
+			Usage: "maximum requests per second sent to the service, 0 for no limit",
+		},
+		cli.BoolFlag{
+			Name:  "rewrite-images",
+			Usage: "point local images in the content sent to the service at the uploaded copies",
+		},
+		cli.BoolFlag{
+			Name:  "process-images",
+			Usage: "resize, re-encode and strip metadata from images and upload size variants",
+		},
+		cli.IntFlag{
+			Name:  "max-image-size",
+			Usage: "largest width or height of a processed image",
+		},
+		cli.IntFlag{
+			Name:  "image-quality",
+			Usage: "JPEG quality of processed images, 1 to 100",
+		},
+		cli.BoolFlag{
+			Name:  "forward-extra",
+			Usage: "send front matter fields the importer doesn't know to the service as extra",
 		},
 		cli.StringFlag{
-			Name:        "serviceUrl",
-			Value:       configSettings.Auth.ServiceURL,
-			Usage:       "",
-			Destination: &configSettings.Auth.ServiceURL,
+			Name:  "timezone",
+			Usage: "site timezone publish dates without an offset are in, such as America/Chicago (default UTC)",
 		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the changes to the service and local files instead of making them",
+		},
+	}
+
+	// tasks created by the command, so a dry run can print what they skipped
+	var commandTasks []*tasks.Task
+	newTask := func() (*tasks.Task, error) {
+		task, err := tasks.NewTask(configSettings)
+		if err == nil {
+			commandTasks = append(commandTasks, task)
+		}
+
+		return task, err
+	}
+
+	// tasks for commands that talk to the service check the credentials before doing anything
+	newServiceTask := func() (*tasks.Task, error) {
+		task, err := newTask()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := task.CheckCredentials(); err != nil {
+			return nil, err
+		}
+
+		return task, nil
+	}
+
+	app.Before = func(c *cli.Context) error {
+		settings, err := config.Load(c.String("config"), c.String("profile"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), 86)
+		}
+
+		if err := applyFlags(c, settings); err != nil {
+			return cli.NewExitError(err.Error(), 86)
+		}
+
+		configSettings = settings
+		return nil
 	}
 
 	app.Commands = []cli.Command{
@@ -49,10 +178,21 @@ func main() {
 			Flags: []cli.Flag{
 				cli.BoolFlag{Name: "force, f"},
 				cli.StringFlag{Name: "filename"},
+				cli.StringFlag{Name: "publish-at", Usage: "publish date, such as 2026-11-02 14:00, tomorrow 9am, next friday or +3d"},
 			},
 			Action: func(c *cli.Context) error {
-				task := tasks.NewTask(configSettings)
-				article, err := task.LoadArticle(c.String("filename"), c.Bool("force"))
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				if c.IsSet("publish-at") {
+					if err := task.SetPublishAt(c.String("publish-at")); err != nil {
+						return cli.NewExitError(err.Error(), 86)
+					}
+				}
+
+				article, err := task.LoadArticle(ctx, c.String("filename"), c.Bool("force"))
 				if err != nil {
 					return cli.NewExitError("Error Message: "+err.Error(), 86)
 				}
@@ -66,24 +206,57 @@ func main() {
 			Usage: "update an existing article",
 			Flags: []cli.Flag{
 				cli.StringFlag{Name: "filename"},
+				cli.BoolFlag{Name: "all", Usage: "send every article, not just changed ones"},
+				cli.BoolFlag{Name: "include-drafts", Usage: "send draft articles too"},
+				cli.IntFlag{Name: "concurrency", Value: 1, Usage: "number of articles sent at once"},
+				cli.BoolFlag{Name: "keep-going", Usage: "attempt every file instead of stopping at the first failure"},
+				cli.StringFlag{Name: "report", Value: "table", Usage: "format of the end of run report, table or json"},
 			},
 			Action: func(c *cli.Context) error {
-				task := tasks.NewTask(configSettings)
-				err := task.UpdateArticles(c.String("filename"), true)
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				if c.String("report") == "json" {
+					task.SetOutput(os.Stderr)
+				}
+
+				options := tasks.UpdateOptions{
+					BatchOptions: tasks.BatchOptions{
+						Concurrency: c.Int("concurrency"),
+						KeepGoing:   c.Bool("keep-going"),
+					},
+					All:           c.Bool("all"),
+					IncludeDrafts: c.Bool("include-drafts"),
+				}
+
+				summary, err := task.UpdateArticles(ctx, c.String("filename"), true, options)
+				printBatchSummary(summary, c.String("report"))
 				if err != nil {
 					return cli.NewExitError(err.Error(), 86)
 				}
 
-				fmt.Printf("Successfull Updated Articles\n ")
+				if c.String("report") != "json" {
+					fmt.Printf("Successfull Updated Articles\n ")
+				}
+
 				return nil
 			},
 		},
 		{
 			Name:  "delete-article",
 			Usage: "delete an existing article",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "id"},
+			},
 			Action: func(c *cli.Context) error {
-				task := tasks.NewTask(configSettings)
-				articleID, err := task.DeleteArticle()
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				articleID, err := task.DeleteArticle(ctx, c.String("id"))
 				if err != nil {
 					return cli.NewExitError(err.Error(), 86)
 				}
@@ -96,8 +269,12 @@ func main() {
 			Name:  "new-link",
 			Usage: "create a new link",
 			Action: func(c *cli.Context) error {
-				task := tasks.NewTask(configSettings)
-				link, err := task.CreateNewLink()
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				link, err := task.CreateNewLink(ctx)
 				if err != nil {
 					return cli.NewExitError(err.Error(), 86)
 				}
@@ -109,9 +286,16 @@ func main() {
 		{
 			Name:  "delete-link",
 			Usage: "delete an existing link",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "id"},
+			},
 			Action: func(c *cli.Context) error {
-				task := tasks.NewTask(configSettings)
-				linkID, err := task.DeleteLink()
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				linkID, err := task.DeleteLink(ctx, c.String("id"))
 				if err != nil {
 					return cli.NewExitError(err.Error(), 86)
 				}
@@ -120,26 +304,481 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "pull-article",
+			Usage: "write an article from the service and its images to a markdown file",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "id"},
+				cli.StringFlag{Name: "dir"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				article, err := task.PullArticle(ctx, c.String("id"), c.String("dir"))
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				fmt.Printf("Successfull Pulled Article %s (Id: %s) to %s\n", article.Title, article.ID, article.DataSource)
+				return nil
+			},
+		},
+		{
+			Name:  "export-articles",
+			Usage: "write every article on the service and their images to markdown files",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dir"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				articles, err := task.ExportArticles(ctx, c.String("dir"))
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				fmt.Printf("Successfull Exported %d Articles\n", len(articles))
+				return nil
+			},
+		},
+		{
+			Name:  "sync",
+			Usage: "push local changes and pull remote changes between the article tree and the service",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dir"},
+				cli.BoolFlag{Name: "push-only"},
+				cli.BoolFlag{Name: "pull-only"},
+				cli.BoolFlag{Name: "include-drafts", Usage: "sync draft articles too"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				options := tasks.SyncOptions{
+					PushOnly:      c.Bool("push-only"),
+					PullOnly:      c.Bool("pull-only"),
+					IncludeDrafts: c.Bool("include-drafts"),
+				}
+
+				results, err := task.Sync(ctx, c.String("dir"), options)
+				printSyncResults(results)
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				for _, result := range results {
+					if result.Action == tasks.SyncFailed {
+						return cli.NewExitError("Some articles could not be synced", 86)
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:  "publish-due",
+			Usage: "publish scheduled articles whose publish date has passed",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dir"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				articles, err := task.PublishDue(ctx, c.String("dir"), time.Now())
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				fmt.Printf("Successfull Published %d Articles\n", len(articles))
+				return nil
+			},
+		},
+		{
+			Name:  "lint",
+			Usage: "check the article files for problems without contacting the service",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dir"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				diagnostics, err := task.Lint(c.String("dir"))
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				for _, diagnostic := range diagnostics {
+					fmt.Println(diagnostic)
+				}
+
+				if len(diagnostics) > 0 {
+					return cli.NewExitError(fmt.Sprintf("%d problems found", len(diagnostics)), 86)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:  "watch",
+			Usage: "republish articles when their markdown files or images change",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dir"},
+				cli.DurationFlag{Name: "debounce", Value: 500 * time.Millisecond, Usage: "time to wait after the last change before publishing"},
+				cli.BoolFlag{Name: "include-drafts", Usage: "publish draft articles too"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newServiceTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				options := tasks.WatchOptions{
+					Debounce:      c.Duration("debounce"),
+					IncludeDrafts: c.Bool("include-drafts"),
+				}
+
+				if err := task.Watch(ctx, c.String("dir"), options); err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:  "normalize",
+			Usage: "rewrite the dataSource of existing article files relative to the content root",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dir"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				files, err := task.Normalize(ctx, c.String("dir"))
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				fmt.Printf("Successfull Normalized %d Files\n", len(files))
+				return nil
+			},
+		},
+		{
+			Name:  "login",
+			Usage: "save credentials and auth key for the profile in the encrypted credential file",
+			Action: func(c *cli.Context) error {
+				prompter, err := tasks.NewPrompter(configSettings.NoInput, configSettings.AnswersFile)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				err = tasks.Login(ctx, configSettings, prompter)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				fmt.Printf("Successfull Logged In to %s (profile %s)\n", configSettings.Auth.ServiceURL, configSettings.Profile)
+				return nil
+			},
+		},
+		{
+			Name:  "logout",
+			Usage: "remove saved credentials and the cached auth token for the profile",
+			Action: func(c *cli.Context) error {
+				prompter, err := tasks.NewPrompter(configSettings.NoInput, configSettings.AnswersFile)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				err = tasks.Logout(configSettings, prompter)
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				fmt.Printf("Successfull Logged Out of profile %s\n", configSettings.Profile)
+				return nil
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "show the resolved settings",
+			Action: func(c *cli.Context) error {
+				return configSettings.Print(os.Stdout)
+			},
+		},
 		{
 			Name:  "import-article",
 			Usage: "create article from hugo yml file",
 			Flags: []cli.Flag{
 				cli.BoolFlag{Name: "force, f"},
 				cli.StringFlag{Name: "filename"},
+				cli.IntFlag{Name: "concurrency", Value: 1, Usage: "number of files imported at once"},
+				cli.BoolFlag{Name: "keep-going", Usage: "attempt every file instead of stopping at the first failure"},
+				cli.StringFlag{Name: "report", Value: "table", Usage: "format of the end of run report, table or json"},
 			},
 			Action: func(c *cli.Context) error {
-				task := tasks.NewTask(configSettings)
-				err := task.ImportArticles(c.String("filename"))
+				task, err := newTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				if c.String("report") == "json" {
+					task.SetOutput(os.Stderr)
+				}
+
+				options := tasks.BatchOptions{
+					Concurrency: c.Int("concurrency"),
+					KeepGoing:   c.Bool("keep-going"),
+				}
+
+				summary, err := task.ImportArticles(ctx, c.String("filename"), options)
+				printBatchSummary(summary, c.String("report"))
 				if err != nil {
 					return cli.NewExitError("Error Message: "+err.Error(), 86)
 				}
 
-				fmt.Printf("Successfull Imported Yaml files")
+				if c.String("report") != "json" {
+					fmt.Printf("Successfull Imported Yaml files")
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:  "import-jekyll",
+			Usage: "create articles from the posts of a jekyll site",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "site", Usage: "folder of the jekyll site, containing _posts and assets"},
+				cli.StringFlag{Name: "dir", Usage: "folder the article folders are created in"},
+				cli.IntFlag{Name: "concurrency", Value: 1, Usage: "number of posts imported at once"},
+				cli.BoolFlag{Name: "keep-going", Usage: "attempt every post instead of stopping at the first failure"},
+				cli.StringFlag{Name: "report", Value: "table", Usage: "format of the end of run report, table or json"},
+			},
+			Action: func(c *cli.Context) error {
+				task, err := newTask()
+				if err != nil {
+					return cli.NewExitError(err.Error(), 86)
+				}
+
+				if c.String("report") == "json" {
+					task.SetOutput(os.Stderr)
+				}
+
+				options := tasks.BatchOptions{
+					Concurrency: c.Int("concurrency"),
+					KeepGoing:   c.Bool("keep-going"),
+				}
+
+				summary, err := task.ImportJekyll(ctx, c.String("site"), c.String("dir"), options)
+				printBatchSummary(summary, c.String("report"))
+				if err != nil {
+					return cli.NewExitError("Error Message: "+err.Error(), 86)
+				}
+
+				if c.String("report") != "json" {
+					fmt.Printf("Successfull Imported Jekyll Posts\n")
+				}
+
 				return nil
 			},
 		},
 	}
 
+	// an action's exit error ends the program before app.After runs, so each action prints what a
+	// dry run skipped before returning
+	for i := range app.Commands {
+		action, ok := app.Commands[i].Action.(func(*cli.Context) error)
+		if !ok {
+			continue
+		}
+
+		app.Commands[i].Action = func(c *cli.Context) error {
+			err := action(c)
+			for _, task := range commandTasks {
+				task.PrintDryRun()
+			}
+
+			return err
+		}
+	}
+
 	app.Run(os.Args)
+}
+
+//applyFlags overrides settings with the global flags that were given on the command line
+func applyFlags(c *cli.Context, settings *config.Settings) error {
+	if c.IsSet("username") {
+		settings.Auth.UserName = c.String("username")
+	}
+
+	if c.IsSet("password") {
+		settings.Auth.Password = c.String("password")
+	}
+
+	if c.Bool("password-stdin") {
+		password, err := readPassword(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("Could not read password from stdin: %s", err.Error())
+		}
+
+		settings.Auth.Password = password
+	}
+
+	if c.IsSet("password-file") {
+		file, err := os.Open(c.String("password-file"))
+		if err != nil {
+			return fmt.Errorf("Could not open password file: %s", err.Error())
+		}
+
+		defer file.Close()
+		password, err := readPassword(file)
+		if err != nil {
+			return fmt.Errorf("Could not read password file: %s", err.Error())
+		}
+
+		settings.Auth.Password = password
+	}
+
+	if c.IsSet("key-file") {
+		settings.Credentials.KeyFile = c.String("key-file")
+	}
+
+	if c.IsSet("serviceUrl") {
+		settings.Auth.ServiceURL = c.String("serviceUrl")
+	}
+
+	if c.IsSet("content-root") {
+		settings.ContentRoot = c.String("content-root")
+	}
+
+	if c.IsSet("cache-token") {
+		settings.Auth.CacheToken = c.Bool("cache-token")
+	}
+
+	if c.IsSet("timeout") {
+		settings.HTTP.Timeout = c.Duration("timeout")
+	}
+
+	if c.IsSet("retries") {
+		settings.HTTP.Retries = c.Int("retries")
+	}
+
+	if c.IsSet("rate-limit") {
+		settings.HTTP.RateLimit = c.Float64("rate-limit")
+	}
+
+	if c.IsSet("process-images") {
+		settings.Images.Process = c.Bool("process-images")
+	}
+
+	if c.IsSet("max-image-size") {
+		if c.Int("max-image-size") <= 0 {
+			return cli.NewExitError(fmt.Sprintf("Invalid max image size %d, must be more than 0", c.Int("max-image-size")), 86)
+		}
+
+		settings.Images.MaxDimension = c.Int("max-image-size")
+	}
+
+	if c.IsSet("image-quality") {
+		if c.Int("image-quality") < 1 || c.Int("image-quality") > 100 {
+			return cli.NewExitError(fmt.Sprintf("Invalid image quality %d, must be 1 to 100", c.Int("image-quality")), 86)
+		}
+
+		settings.Images.Quality = c.Int("image-quality")
+	}
+
+	if c.IsSet("forward-extra") {
+		settings.ForwardExtra = c.Bool("forward-extra")
+	}
+
+	if c.IsSet("rewrite-images") {
+		settings.RewriteImages = c.Bool("rewrite-images")
+	}
+
+	if c.IsSet("timezone") {
+		if err := settings.SetTimezone(c.String("timezone")); err != nil {
+			return err
+		}
+	}
+
+	if c.IsSet("idempotency-keys") {
+		settings.HTTP.IdempotencyKeys = c.Bool("idempotency-keys")
+	}
+
+	settings.DryRun = c.Bool("dry-run")
+	settings.NoInput = c.Bool("no-input")
+	settings.AnswersFile = c.String("answers")
+
+	// stdin holds the password, so there is nothing left to answer prompts with
+	if c.Bool("password-stdin") {
+		settings.NoInput = true
+	}
+
+	return nil
+}
+
+//printBatchSummary prints the results of a batch run as a table or as JSON
+func printBatchSummary(summary *tasks.BatchSummary, format string) {
+	if summary == nil {
+		return
+	}
+
+	if format == "json" {
+		summary.WriteJSON(os.Stdout)
+		return
+	}
+
+	summary.WriteTable(os.Stdout)
+}
+
+//printSyncResults prints every article that sync did something with, followed by totals
+func printSyncResults(results []tasks.SyncResult) {
+	counts := make(map[tasks.SyncAction]int)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, result := range results {
+		counts[result.Action]++
+		if result.Action == tasks.SyncUnchanged {
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Action, result.Path, result.ID, result.Reason)
+	}
+
+	tw.Flush()
+
+	fmt.Printf("%d pushed, %d pulled, %d unchanged, %d conflicts, %d skipped, %d failed\n",
+		counts[tasks.SyncPush], counts[tasks.SyncPull], counts[tasks.SyncUnchanged],
+		counts[tasks.SyncConflict], counts[tasks.SyncSkipped], counts[tasks.SyncFailed])
+}
+
+//readPassword reads the first line of r as a password
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password is empty")
+	}
 
+	return password, nil
 }
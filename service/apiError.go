@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+//maxErrorBody limits how much of an error response is kept
+const maxErrorBody = 64 * 1024
+
+//APIError is returned when the article service responds with an unexpected status code
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Endpoint   string
+	Message    string
+	Body       string
+}
+
+func (err *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", err.Method, err.Endpoint, err.Status)
+	if err.Message != "" {
+		msg = msg + ": " + err.Message
+	}
+
+	return msg
+}
+
+//IsNotFound returns true when err is an APIError for a missing resource
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+//IsUnauthorized returns true when err is an APIError for rejected credentials
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized) || hasStatus(err, http.StatusForbidden)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+//checkResponse returns an APIError when the response status code is not a success
+func checkResponse(res *http.Response, method, endpoint string) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	return newAPIError(res, method, endpoint)
+}
+
+func newAPIError(res *http.Response, method, endpoint string) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Method:     method,
+		Endpoint:   endpoint,
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if err != nil {
+		return apiErr
+	}
+
+	apiErr.Body = string(body)
+	apiErr.Message = errorMessage(body)
+
+	return apiErr
+}
+
+//errorMessage pulls the message out of the common server error bodies
+func errorMessage(body []byte) string {
+	serverError := struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(body, &serverError); err == nil {
+		if serverError.Message != "" {
+			return serverError.Message
+		}
+
+		return serverError.Error
+	}
+
+	text := strings.TrimSpace(string(body))
+	if strings.HasPrefix(text, "<") {
+		return ""
+	}
+
+	return text
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNoCredentials = errors.New("Unable to get user token: username and password are required")
+
+//tokenExpirySkew refreshes tokens slightly before the server would reject them
+const tokenExpirySkew = 30 * time.Second
+
+//cachedToken is the on disk representation of an auth token
+type cachedToken struct {
+	ServiceURL string    `json:"serviceUrl"`
+	Username   string    `json:"username"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	AuthUser   AuthUser  `json:"authUser"`
+}
+
+//HasToken returns true when a usable auth token is cached in memory or on disk
+func (httpService *HTTPService) HasToken() bool {
+	httpService.tokenLock.Lock()
+	defer httpService.tokenLock.Unlock()
+
+	return httpService.cachedUser() != nil
+}
+
+//ClearToken discards the cached auth token and removes the token cache file
+func (httpService *HTTPService) ClearToken() error {
+	httpService.tokenLock.Lock()
+	defer httpService.tokenLock.Unlock()
+
+	httpService.authUser = nil
+	httpService.expiresAt = time.Time{}
+
+	if httpService.TokenFile == "" {
+		return nil
+	}
+
+	err := os.Remove(httpService.TokenFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+//Authenticate logs in with the username and password, replacing any cached token
+func (httpService *HTTPService) Authenticate(ctx context.Context) error {
+	httpService.tokenLock.Lock()
+	defer httpService.tokenLock.Unlock()
+
+	authUser, err := httpService.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	httpService.setToken(authUser)
+
+	return nil
+}
+
+//getUserToken returns the cached auth token, logging in when none is available
+func (httpService *HTTPService) getUserToken(ctx context.Context) (*AuthUser, error) {
+	httpService.tokenLock.Lock()
+	defer httpService.tokenLock.Unlock()
+
+	if authUser := httpService.cachedUser(); authUser != nil {
+		return authUser, nil
+	}
+
+	authUser, err := httpService.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	httpService.setToken(authUser)
+
+	return authUser, nil
+}
+
+//setToken keeps a new token in memory and in the cache file.
+//The caller must hold tokenLock.
+func (httpService *HTTPService) setToken(authUser *AuthUser) {
+	httpService.authUser = authUser
+	httpService.expiresAt = tokenExpiry(authUser.Token)
+	httpService.saveToken()
+}
+
+//invalidateToken drops the in memory token if it is still the one that was rejected
+func (httpService *HTTPService) invalidateToken(rejected *AuthUser) {
+	httpService.tokenLock.Lock()
+	defer httpService.tokenLock.Unlock()
+
+	if httpService.authUser == nil || httpService.authUser.Token != rejected.Token {
+		return
+	}
+
+	httpService.authUser = nil
+	httpService.expiresAt = time.Time{}
+	if httpService.TokenFile != "" {
+		os.Remove(httpService.TokenFile)
+	}
+}
+
+//sendAuthorized sends a request with the bearer token, logging in again once if the token is rejected
+func (httpService *HTTPService) sendAuthorized(ctx context.Context, verb string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		currentUser, err := httpService.getUserToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := httpService.send(ctx, verb, false, func() (*http.Request, error) {
+			req, err := newRequest()
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Authorization", "Bearer "+currentUser.Token)
+			return req, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return res, nil
+		}
+
+		res.Body.Close()
+		httpService.invalidateToken(currentUser)
+	}
+}
+
+//cachedUser returns the current token, loading it from the cache file when needed.
+//The caller must hold tokenLock.
+func (httpService *HTTPService) cachedUser() *AuthUser {
+	if httpService.authUser == nil {
+		httpService.loadToken()
+	}
+
+	if httpService.authUser == nil {
+		return nil
+	}
+
+	if !httpService.expiresAt.IsZero() && time.Now().Add(tokenExpirySkew).After(httpService.expiresAt) {
+		httpService.authUser = nil
+		httpService.expiresAt = time.Time{}
+		return nil
+	}
+
+	return httpService.authUser
+}
+
+func (httpService *HTTPService) loadToken() {
+	if httpService.TokenFile == "" {
+		return
+	}
+
+	data, err := ioutil.ReadFile(httpService.TokenFile)
+	if err != nil {
+		return
+	}
+
+	cached := &cachedToken{}
+	if err := json.Unmarshal(data, cached); err != nil {
+		return
+	}
+
+	if cached.ServiceURL != httpService.ServiceURL || cached.AuthUser.Token == "" {
+		return
+	}
+
+	if httpService.Username != "" && cached.Username != httpService.Username {
+		return
+	}
+
+	httpService.authUser = &cached.AuthUser
+	httpService.expiresAt = cached.ExpiresAt
+}
+
+func (httpService *HTTPService) saveToken() {
+	if httpService.TokenFile == "" {
+		return
+	}
+
+	cached := &cachedToken{
+		ServiceURL: httpService.ServiceURL,
+		Username:   httpService.Username,
+		ExpiresAt:  httpService.expiresAt,
+		AuthUser:   *httpService.authUser,
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	// the token cache is an optimisation, so failing to write it is not an error
+	if err := os.MkdirAll(filepath.Dir(httpService.TokenFile), 0700); err != nil {
+		return
+	}
+
+	ioutil.WriteFile(httpService.TokenFile, data, 0600)
+}
+
+//tokenExpiry reads the exp claim of a JWT, returning the zero time when it can't be determined
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	claims := struct {
+		Exp json.Number `json:"exp"`
+	}{}
+
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}
+	}
+
+	exp, err := strconv.ParseInt(claims.Exp.String(), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(exp, 0)
+}
@@ -2,19 +2,15 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
-	"mime/multipart"
+	"net"
 	"net/http"
-	"net/textproto"
-	"os"
-	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"net/url"
 
@@ -27,6 +23,17 @@ type HTTPService struct {
 	AuthKey    string
 	Username   string
 	Password   string
+	TokenFile  string
+	Client     *http.Client
+	Retry      RetryPolicy
+
+	//UploadProgress is told how much of each uploaded file has been sent
+	UploadProgress ProgressFunc
+
+	limiter   *rateLimiter
+	tokenLock sync.Mutex
+	authUser  *AuthUser
+	expiresAt time.Time
 }
 
 //AuthBody authorization body information
@@ -49,191 +56,259 @@ type AuthUser struct {
 }
 
 //NewHTTPService creates a new HTTPService
-func NewHTTPService(settings config.Authorization) *HTTPService {
+func NewHTTPService(settings *config.Settings) *HTTPService {
 	svc := &HTTPService{
-		settings.ServiceURL,
-		settings.AuthKey,
-		settings.UserName,
-		settings.Password,
+		ServiceURL: settings.Auth.ServiceURL,
+		AuthKey:    settings.Auth.AuthKey,
+		Username:   settings.Auth.UserName,
+		Password:   settings.Auth.Password,
+		Client: &http.Client{
+			Transport: newTransport(settings.HTTP.Timeout),
+		},
+		Retry: RetryPolicy{
+			MaxRetries:      settings.HTTP.Retries,
+			BaseDelay:       settings.HTTP.RetryDelay,
+			MaxDelay:        settings.HTTP.MaxRetryDelay,
+			IdempotencyKeys: settings.HTTP.IdempotencyKeys,
+		},
+		limiter: newRateLimiter(settings.HTTP.RateLimit),
+	}
+
+	if settings.Auth.CacheToken {
+		svc.TokenFile = settings.Auth.TokenCacheFile
 	}
 
 	return svc
 }
 
+//newTransport limits each attempt without limiting the time spent sending the request body, so
+//streamed uploads of large files aren't cut off. The timeout applies to connecting and to
+//waiting for the response once the request has been sent.
+func newTransport(timeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if timeout <= 0 {
+		return transport
+	}
+
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	return transport
+}
+
 //Get returns a json payload
-func (httpService *HTTPService) Get(endpoint string, id string, target interface{}) error {
+func (httpService *HTTPService) Get(ctx context.Context, endpoint string, id string, target interface{}) error {
 	serviceURL := httpService.ServiceURL + "/" + endpoint + "/" + id
 
-	r, err := http.Get(serviceURL)
+	r, err := httpService.send(ctx, "GET", false, func() (*http.Request, error) {
+		return http.NewRequest("GET", serviceURL, nil)
+	})
+
 	if err != nil {
 		return err
 	}
 
 	defer r.Body.Close()
+	if err := checkResponse(r, "GET", endpoint+"/"+id); err != nil {
+		return err
+	}
+
 	return json.NewDecoder(r.Body).Decode(target)
 }
 
-//ResolveLink checks the status of a link
-func (httpService *HTTPService) ResolveLink(link string) bool {
-	_, err := url.Parse(link)
-	if err != nil {
-		return false
+//GetList returns the json payload of a list endpoint
+func (httpService *HTTPService) GetList(ctx context.Context, endpoint string, query url.Values, target interface{}) error {
+	serviceURL := httpService.ServiceURL + "/" + endpoint
+	if len(query) > 0 {
+		serviceURL = serviceURL + "?" + query.Encode()
 	}
 
-	r, err := http.Get(link)
+	r, err := httpService.send(ctx, "GET", false, func() (*http.Request, error) {
+		return http.NewRequest("GET", serviceURL, nil)
+	})
+
 	if err != nil {
-		return false
+		return err
 	}
 
-	return r.StatusCode == http.StatusOK
+	defer r.Body.Close()
+	if err := checkResponse(r, "GET", endpoint); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(r.Body).Decode(target)
 }
 
-//Upload uploads and image to a service
-func (httpService *HTTPService) Upload(endpoint, filename string) ([]byte, error) {
-	url := httpService.ServiceURL + "/" + endpoint
+//Download writes the body of an endpoint to w
+func (httpService *HTTPService) Download(ctx context.Context, endpoint string, w io.Writer) error {
+	serviceURL := httpService.ServiceURL + "/" + endpoint
 
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
+	r, err := httpService.send(ctx, "GET", false, func() (*http.Request, error) {
+		return http.NewRequest("GET", serviceURL, nil)
+	})
 
-	bfile := make([]byte, 512)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	ctype := http.DetectContentType(bfile)
-
-	if ctype == "application/octet-stream" {
-		carr := strings.Split(filename, ".")
-		ctype = "image/" + carr[len(carr)-1]
+	defer r.Body.Close()
+	if err := checkResponse(r, "GET", endpoint); err != nil {
+		return err
 	}
 
-	defer file.Close()
+	_, err = io.Copy(w, r.Body)
+	return err
+}
 
-	buffer := &bytes.Buffer{}
-	writer := multipart.NewWriter(buffer)
+//Head returns the headers of an endpoint without downloading its body
+func (httpService *HTTPService) Head(ctx context.Context, endpoint string) (http.Header, error) {
+	serviceURL := httpService.ServiceURL + "/" + endpoint
 
-	header := make(textproto.MIMEHeader)
-	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="image"; filename="%s"`, filename))
-	header.Set("Content-Type", ctype)
+	r, err := httpService.send(ctx, "HEAD", false, func() (*http.Request, error) {
+		return http.NewRequest("HEAD", serviceURL, nil)
+	})
 
-	filewriter, err := writer.CreatePart(header)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = io.Copy(filewriter, file)
-	if err != nil {
+	r.Body.Close()
+	if err := checkResponse(r, "HEAD", endpoint); err != nil {
 		return nil, err
 	}
 
-	writer.Close()
-	req, err := http.NewRequest("POST", url, buffer)
+	return r.Header, nil
+}
+
+//ResolveLink checks the status of a link
+func (httpService *HTTPService) ResolveLink(ctx context.Context, link string) bool {
+	_, err := url.Parse(link)
 	if err != nil {
-		return nil, err
+		return false
+	}
+
+	r, err := httpService.send(ctx, "HEAD", false, func() (*http.Request, error) {
+		return http.NewRequest("HEAD", link, nil)
+	})
+
+	if err != nil {
+		return false
 	}
 
-	contenttype := writer.FormDataContentType()
-	req.Header.Set("Content-Type", contenttype)
+	r.Body.Close()
+	return r.StatusCode == http.StatusOK
+}
 
-	currentUser, err := httpService.getUserToken()
+//Upload uploads and image to a service, streaming the file rather than holding it in memory
+func (httpService *HTTPService) Upload(ctx context.Context, endpoint, filename string) ([]byte, error) {
+	url := httpService.ServiceURL + "/" + endpoint
+
+	upload, err := newMultipartUpload(filename, "image", httpService.UploadProgress)
 	if err != nil {
-		log.Printf("Can't get user token: %s", err.Error())
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+currentUser.Token)
+	contentLength := upload.ContentLength()
+
+	res, err := httpService.sendAuthorized(ctx, "POST", func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, upload.Body())
+		if err != nil {
+			return nil, err
+		}
+
+		req.ContentLength = contentLength
+		req.Header.Set("Content-Type", upload.FormDataContentType())
+		req.Header.Set(ContentHashHeader, upload.hash)
+		return req, nil
+	})
 
-	client := &http.Client{}
-	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusCreated {
-		err = fmt.Errorf("Unable to save file: statusCode %s", res.Status)
-		return nil, err
+		return nil, newAPIError(res, "POST", endpoint)
 	}
 
 	return ioutil.ReadAll(res.Body)
 }
 
-//SendRequest sends an http request
-func (httpService *HTTPService) SendRequest(verb string, endpoint string, target interface{}) error {
+//SendRequest sends an http request, decoding the response into target when there is one
+func (httpService *HTTPService) SendRequest(ctx context.Context, verb string, endpoint string, target interface{}) error {
 	url := httpService.ServiceURL + "/" + endpoint
 
-	currentUser, err := httpService.getUserToken()
-	if err != nil {
-		log.Fatal(err)
+	var b []byte
+	if target != nil {
+		var err error
+		b, err = json.Marshal(target)
+		if err != nil {
+			return err
+		}
 	}
 
-	var req *http.Request
+	res, err := httpService.sendAuthorized(ctx, verb, func() (*http.Request, error) {
+		if b == nil {
+			return http.NewRequest(verb, url, nil)
+		}
 
-	if target != nil {
-		b, err := json.Marshal(target)
+		req, err := http.NewRequest(verb, url, bytes.NewReader(b))
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 
-		req, err = http.NewRequest(verb, url, bytes.NewReader(b))
 		req.Header.Set("Content-Type", "application/json")
-	} else {
-		req, err = http.NewRequest(verb, url, nil)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+currentUser.Token)
+		return req, nil
+	})
 
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	client := &http.Client{}
-	res, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("Error sending request: Status Code - " + strconv.Itoa(res.StatusCode))
+		return err
 	}
 
 	defer res.Body.Close()
+	if err := checkResponse(res, verb, endpoint); err != nil {
+		return err
+	}
 
-	if err != nil {
-		log.Fatal(err)
+	if target == nil || res.StatusCode == http.StatusNoContent {
+		return nil
 	}
 
-	if target != nil {
-		err = json.NewDecoder(res.Body).Decode(target)
+	err = json.NewDecoder(res.Body).Decode(target)
+	if err == io.EOF {
+		return nil
 	}
 
 	return err
 }
 
-func (httpService *HTTPService) getUserToken() (*AuthUser, error) {
+func (httpService *HTTPService) login(ctx context.Context) (*AuthUser, error) {
+	if httpService.Username == "" || httpService.Password == "" {
+		return nil, errNoCredentials
+	}
+
 	authstring := basicAuth(httpService.Username, httpService.Password)
 	serviceURL := httpService.ServiceURL + "/auth?access_token=" + httpService.AuthKey
 
-	req, err := http.NewRequest("POST", serviceURL, nil)
-	req.Header.Set("Authorization", "Basic "+authstring)
-	req.Header.Set("Content-Type", "application/json")
-
-	if err != nil {
-		log.Fatal(err)
-	}
+	// logging in creates nothing on the server, so it is always safe to retry
+	res, err := httpService.send(ctx, "POST", true, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", serviceURL, nil)
+		if err != nil {
+			return nil, err
+		}
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+		req.Header.Set("Authorization", "Basic "+authstring)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer res.Body.Close()
-	if res.StatusCode != 201 {
-		err = errors.New("Unable to get user token: Status Code - " + strconv.Itoa(res.StatusCode))
 		return nil, err
 	}
 
-	if err != nil {
-		log.Fatal(err)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return nil, newAPIError(res, "POST", "auth")
 	}
 
 	authUser := &AuthUser{}
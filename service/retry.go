@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//maxRetryAfter caps how long a Retry-After header can make a request wait
+const maxRetryAfter = 2 * time.Minute
+
+//RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	MaxRetries      int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	IdempotencyKeys bool
+}
+
+//retryable returns true when requests with the verb can safely be sent again
+func (policy RetryPolicy) retryable(verb string) bool {
+	switch verb {
+	case "GET", "HEAD", "PUT", "DELETE", "OPTIONS":
+		return true
+	case "POST":
+		return policy.IdempotencyKeys
+	}
+
+	return false
+}
+
+//backoff returns the jittered exponential delay before the given retry
+func (policy RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(policy.BaseDelay) * math.Pow(2, float64(retry))
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+
+	half := delay / 2
+	return time.Duration(half + mathrand.Float64()*half)
+}
+
+//send sends the request built by newRequest, retrying transient failures of idempotent requests.
+//Passing safe marks a request as retryable whatever its verb.
+func (httpService *HTTPService) send(ctx context.Context, verb string, safe bool, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	policy := httpService.Retry
+	canRetry := safe || policy.retryable(verb)
+
+	idempotencyKey := ""
+	if verb == "POST" && policy.IdempotencyKeys {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for retry := 0; ; retry++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.WithContext(ctx)
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+
+		if httpService.limiter != nil {
+			if err := httpService.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := httpService.client().Do(req)
+		if !canRetry || retry >= policy.MaxRetries || ctx.Err() != nil {
+			return res, err
+		}
+
+		delay := policy.backoff(retry)
+		if err == nil {
+			if !retryableStatus(res.StatusCode) {
+				return res, nil
+			}
+
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (httpService *HTTPService) client() *http.Client {
+	if httpService.Client != nil {
+		return httpService.Client
+	}
+
+	return http.DefaultClient
+}
+
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+//parseRetryAfter reads a Retry-After header given in seconds or as an http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+
+	return delay, true
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(b)
+}
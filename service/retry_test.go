@@ -0,0 +1,84 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"soon", 0, false},
+		{"0", 0, true},
+		{"5", 5 * time.Second, true},
+		{"-5", 0, true},
+		{"3600", maxRetryAfter, true},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), maxRetryAfter, true},
+	}
+
+	for _, test := range tests {
+		got, ok := parseRetryAfter(test.value)
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", test.value, got, ok, test.want, test.ok)
+		}
+	}
+
+	// an http date is only precise to the second
+	value := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if got, ok := parseRetryAfter(value); !ok || got < 28*time.Second || got > 30*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, %v, want about 30s", value, got, ok)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		retry int
+		delay time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{20, time.Second},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			got := policy.backoff(test.retry)
+			if got < test.delay/2 || got > test.delay {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", test.retry, got, test.delay/2, test.delay)
+				break
+			}
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		verb            string
+		idempotencyKeys bool
+		want            bool
+	}{
+		{"GET", false, true},
+		{"PUT", false, true},
+		{"DELETE", false, true},
+		{"POST", false, false},
+		{"POST", true, true},
+		{"PATCH", true, false},
+	}
+
+	for _, test := range tests {
+		policy := RetryPolicy{IdempotencyKeys: test.idempotencyKeys}
+		if got := policy.retryable(test.verb); got != test.want {
+			t.Errorf("retryable(%s) with idempotency keys %v = %v, want %v", test.verb, test.idempotencyKeys, got, test.want)
+		}
+	}
+}
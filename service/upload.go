@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//ContentHashHeader carries the hex SHA-256 of an uploaded image, on the upload and when the image is fetched
+const ContentHashHeader = "X-Content-SHA256"
+
+//ProgressFunc is told how much of the file at path has been sent, total is -1 when the size isn't known
+type ProgressFunc func(path string, sent, total int64)
+
+//imageTypes are the types of image formats http.DetectContentType doesn't recognise or that
+//mime.TypeByExtension may not know on every system
+var imageTypes = map[string]string{
+	".avif": "image/avif",
+	".bmp":  "image/bmp",
+	".gif":  "image/gif",
+	".ico":  "image/x-icon",
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+//contentType works out the type of a file from the start of its contents, falling back to its extension
+func contentType(filename string, header []byte) string {
+	sniffed := http.DetectContentType(header)
+	if strings.HasPrefix(sniffed, "image/") {
+		return sniffed
+	}
+
+	// avif is an ISO media file with an avif brand
+	if len(header) >= 12 && string(header[4:8]) == "ftyp" && (string(header[8:12]) == "avif" || string(header[8:12]) == "avis") {
+		return "image/avif"
+	}
+
+	// svg sniffs as text or xml
+	if (strings.HasPrefix(sniffed, "text/xml") || strings.HasPrefix(sniffed, "text/plain")) && bytes.Contains(header, []byte("<svg")) {
+		return "image/svg+xml"
+	}
+
+	extension := strings.ToLower(filepath.Ext(filename))
+	if ctype, ok := imageTypes[extension]; ok {
+		return ctype
+	}
+
+	if ctype := mime.TypeByExtension(extension); ctype != "" {
+		return ctype
+	}
+
+	return "application/octet-stream"
+}
+
+//multipartUpload streams a file as the single part of a multipart form
+type multipartUpload struct {
+	path     string
+	field    string
+	filename string
+	ctype    string
+	hash     string
+	size     int64
+	boundary string
+	progress ProgressFunc
+}
+
+//newMultipartUpload sniffs the type, size and hash of the file at path
+func newMultipartUpload(path, field string, progress ProgressFunc) (*multipartUpload, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	upload := &multipartUpload{
+		path:     path,
+		field:    field,
+		filename: filepath.Base(path),
+		ctype:    contentType(path, header[:n]),
+		size:     -1,
+		boundary: multipart.NewWriter(nil).Boundary(),
+		progress: progress,
+	}
+
+	if info.Mode().IsRegular() {
+		upload.size = info.Size()
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+
+	upload.hash = hex.EncodeToString(hash.Sum(nil))
+	return upload, nil
+}
+
+//FormDataContentType returns the Content-Type of the request body
+func (upload *multipartUpload) FormDataContentType() string {
+	return "multipart/form-data; boundary=" + upload.boundary
+}
+
+//ContentLength returns the length of the request body, -1 when the file size isn't known
+func (upload *multipartUpload) ContentLength() int64 {
+	if upload.size < 0 {
+		return -1
+	}
+
+	// the form around the file is the same whatever the file holds, so measure it empty
+	counter := &countingWriter{}
+	writer := upload.writer(counter)
+	writer.CreatePart(upload.partHeader())
+	writer.Close()
+
+	return counter.n + upload.size
+}
+
+//Body returns a reader streaming the form, which writes the file through a pipe as it is read
+func (upload *multipartUpload) Body() io.ReadCloser {
+	reader, writer := io.Pipe()
+	return &pipeBody{upload: upload, reader: reader, writer: writer}
+}
+
+//pipeBody only starts writing the form once the request is sent, so a request that never
+//is doesn't leave the writer blocked
+type pipeBody struct {
+	upload *multipartUpload
+	start  sync.Once
+	reader *io.PipeReader
+	writer *io.PipeWriter
+}
+
+func (body *pipeBody) Read(p []byte) (int, error) {
+	body.start.Do(func() {
+		go func() {
+			body.writer.CloseWithError(body.upload.write(body.writer))
+		}()
+	})
+
+	return body.reader.Read(p)
+}
+
+func (body *pipeBody) Close() error {
+	return body.reader.Close()
+}
+
+func (upload *multipartUpload) write(w io.Writer) error {
+	file, err := os.Open(upload.path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	writer := upload.writer(w)
+	part, err := writer.CreatePart(upload.partHeader())
+	if err != nil {
+		return err
+	}
+
+	var source io.Reader = file
+	if upload.progress != nil {
+		source = &progressReader{reader: file, path: upload.path, total: upload.size, progress: upload.progress}
+	}
+
+	if _, err := io.Copy(part, source); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (upload *multipartUpload) writer(w io.Writer) *multipart.Writer {
+	writer := multipart.NewWriter(w)
+	writer.SetBoundary(upload.boundary)
+	return writer
+}
+
+func (upload *multipartUpload) partHeader() textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, upload.field, escapeQuotes(upload.filename)))
+	header.Set("Content-Type", upload.ctype)
+	return header
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+//countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+//progressReader reports how much of a file has been read
+type progressReader struct {
+	reader   io.Reader
+	path     string
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.sent += int64(n)
+	if n > 0 || err == io.EOF {
+		r.progress(r.path, r.sent, r.total)
+	}
+
+	return n, err
+}
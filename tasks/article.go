@@ -1,15 +1,16 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/ericaro/frontmatter"
 )
 
 //Article represents article information
@@ -20,11 +21,17 @@ type Article struct {
 	Images      []string  `json:"images"`
 	Banner      string    `json:"banner"`
 	PublishDate time.Time `json:"publishDate"`
+	Status      string    `json:"status"`
 	DataSource  string    `json:"dataSource"`
 	Author      string    `json:"author"`
 	Categories  []string  `json:"categories"`
 	Tags        []string  `json:"tags"`
 	Content     string    `json:"content"`
+
+	//ImageVariants maps each image filename to the filename of its variants by variant name
+	ImageVariants map[string]map[string]string `json:"imageVariants,omitempty"`
+	//Extra holds the front matter fields the importer doesn't know, when they are forwarded
+	Extra map[string]interface{} `json:"extra,omitempty"`
 }
 
 //ImportArticle represents and article that can be marshalled to yaml
@@ -35,11 +42,12 @@ type ImportArticle struct {
 	Images      []string `yaml:"images"`
 	Banner      string   `yaml:"banner"`
 	PublishDate string   `yaml:"publishDate"`
+	Status      string   `yaml:"status,omitempty"`
 	DataSource  string   `yaml:"dataSource"`
 	Author      string   `yaml:"author"`
 	Categories  []string `yaml:"categories"`
 	Tags        []string `yaml:"tags"`
-	Content     string   `fm:"content" yaml:"-"`
+	Content     string   `yaml:"-"`
 }
 
 //HugoArticle represents and article that can be marshalled to yaml
@@ -48,62 +56,64 @@ type HugoArticle struct {
 	URL        string   `yaml:"url"`
 	Banner     string   `yaml:"banner"`
 	Date       string   `yaml:"date"`
+	Draft      bool     `yaml:"draft"`
 	Author     string   `yaml:"author"`
 	Categories []string `yaml:"categories"`
 	Tags       []string `yaml:"tags"`
 	Layout     string   `yaml:"layout"`
-	Content    string   `fm:"content" yaml:"-"`
+	Content    string   `yaml:"-"`
 }
 
 //DeleteArticle deletes the specified article
-func (articleTask *Task) DeleteArticle() (string, error) {
-	id := AskForStringValue("Article Id", "", true)
-	if articleTask.service.Username == "" {
-		articleTask.service.Username = AskForStringValue("Username", "", true)
-	}
-
-	if articleTask.service.Password == "" {
-		articleTask.service.Password = AskForStringValue("Password", "", true)
-	}
-
-	if articleTask.service.ServiceURL == "" {
-		articleTask.service.ServiceURL = AskForStringValue("Service Url", "", true)
-	}
-
-	if articleTask.service.AuthKey == "" {
-		log.Fatal("AuthKey environment variable must be set.")
+func (articleTask *Task) DeleteArticle(ctx context.Context, id string) (string, error) {
+	var err error
+	if id == "" {
+		if id, err = articleTask.prompter.String("Article Id", id, true); err != nil {
+			return id, err
+		}
 	}
 
-	requestURL := "articles/" + id
-
-	return id, articleTask.service.SendRequest("DELETE", requestURL, nil)
+	return id, articleTask.store.DeleteArticle(ctx, id)
 }
 
 //GetArticle gets an article by datasource
-func (articleTask *Task) GetArticle(id string) (*Article, error) {
+func (articleTask *Task) GetArticle(ctx context.Context, id string) (*Article, error) {
+	var err error
 	if id == "" {
-		id = AskForStringValue("Article Id", "", true)
-	}
-
-	var article = &Article{}
-	err := articleTask.service.Get("articles", id, article)
-
-	if err != nil {
-		return article, err
+		if id, err = articleTask.prompter.String("Article Id", id, true); err != nil {
+			return nil, err
+		}
 	}
 
-	return article, err
+	return articleTask.store.GetArticle(ctx, id)
 }
 
 //LoadArticle loads an existing article
-func (articleTask *Task) LoadArticle(fileName string, bypassQuestions bool) (*Article, error) {
+func (articleTask *Task) LoadArticle(ctx context.Context, fileName string, bypassQuestions bool) (*Article, error) {
+	var err error
 	if fileName == "" {
-		fileName = AskForStringValue("Import File location", "", false)
+		if fileName, err = articleTask.prompter.String("Import File location", fileName, true); err != nil {
+			return nil, err
+		}
 	}
 
+	article, err := articleTask.readArticleFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !articleTask.publishAt.IsZero() {
+		article.PublishDate = articleTask.publishAt
+	}
+
+	return articleTask.SaveArticle(ctx, article, bypassQuestions)
+}
+
+//readArticleFile reads an ImportArticle markdown file into an Article
+func (articleTask *Task) readArticleFile(fileName string) (*Article, error) {
 	var article = &Article{
 		Title:       "",
-		PublishDate: time.Now(),
+		PublishDate: time.Now().Truncate(time.Second),
 		URL:         "",
 		Banner:      "",
 		DataSource:  "",
@@ -117,87 +127,110 @@ func (articleTask *Task) LoadArticle(fileName string, bypassQuestions bool) (*Ar
 	}
 
 	importfile := new(ImportArticle)
-	err = frontmatter.Unmarshal(artfile, importfile)
+	content, fields, err := unmarshalFrontMatter(artfile, importfile)
 	if err != nil {
 		msg := fmt.Errorf("Error unmarshaling yaml file: %s", err.Error())
 		return nil, msg
 	}
 
+	importfile.Content = content
+	if articleTask.settings.ForwardExtra {
+		article.Extra = extraFields(fields)
+	}
+
 	if importfile.ID != "" {
 		article.ID = importfile.ID
 	}
 
-	importPublishDate, err := time.Parse("01/02/2006", importfile.PublishDate)
-	if err == nil {
-		article.PublishDate = importPublishDate
+	if importfile.PublishDate != "" {
+		article.PublishDate, err = parseDate(importfile.PublishDate, articleTask.location())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid publishDate in %s: %s", fileName, err.Error())
+		}
+	}
+
+	article.Status, err = parseStatus(importfile.Status)
+	if err != nil {
+		return nil, err
 	}
 
 	article.Title = importfile.Title
 	article.URL = importfile.URL
 	article.Author = importfile.Author
+	if article.Author == "" {
+		article.Author = articleTask.settings.Defaults.Author
+	}
 
 	article.Banner = importfile.Banner
-	article.DataSource = fileName
+	article.DataSource = articleTask.dataSource(fileName)
 	article.Categories = importfile.Categories
 	article.Tags = importfile.Tags
 	article.Images = importfile.Images
 	article.Content = importfile.Content
 
-	return articleTask.SaveArticle(article, bypassQuestions)
+	return article, nil
 }
 
 //ImportArticles imports list of articles in path
-func (articleTask *Task) ImportArticles(filedir string) error {
+func (articleTask *Task) ImportArticles(ctx context.Context, filedir string, options BatchOptions) (*BatchSummary, error) {
+	var err error
 	if filedir == "" {
-		filedir = AskForStringValue("Import File or Folder", "", false)
+		if filedir, err = articleTask.prompter.String("Import File or Folder", filedir, true); err != nil {
+			return nil, err
+		}
 	}
 
 	isdir, err := isDirectory(filedir)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	paths := []string{filedir}
 	if isdir {
 		files, err := ioutil.ReadDir(filedir)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
+		paths = nil
 		for _, f := range files {
-
 			filename := f.Name()
 			extension := filepath.Ext(filename)
-			// fmt.Printf("filename: %s ext: %s \n ", filename, extension)
 
 			if extension == ".md" {
-				importfilepath := filedir + "/" + filename
-				fmt.Printf("importing file: %s \n ", importfilepath)
-
-				_, err := articleTask.ImportArticle(importfilepath)
-				if err != nil {
-					fmt.Printf("error: %s \n ", err.Error())
-					return err
-				}
+				paths = append(paths, filedir+"/"+filename)
 			}
 		}
-	} else {
-		_, err := articleTask.ImportArticle(filedir)
-		return err
 	}
 
-	return nil
+	summary := articleTask.runBatch(ctx, paths, options, func(ctx context.Context, jobTask *Task, importfilepath string) BatchResult {
+		jobTask.printf("importing file: %s \n ", importfilepath)
+
+		article, err := jobTask.ImportArticle(importfilepath)
+		if err != nil {
+			jobTask.printf("error: %s \n ", err.Error())
+			return BatchResult{Status: BatchFailed, Err: err}
+		}
+
+		return BatchResult{ID: article.ID, Status: BatchImported}
+	})
+
+	return summary, summary.err(ctx)
 }
 
 //ImportArticle loads an existing article
 func (articleTask *Task) ImportArticle(fileName string) (*Article, error) {
+	var err error
 	if fileName == "" {
-		fileName = AskForStringValue("Import File location", "", false)
+		if fileName, err = articleTask.prompter.String("Import File location", fileName, true); err != nil {
+			return nil, err
+		}
 	}
 
 	var article = &Article{
 		Title:       "",
-		PublishDate: time.Now(),
+		PublishDate: time.Now().Truncate(time.Second),
 		URL:         "",
 		Banner:      "",
 		DataSource:  "",
@@ -211,18 +244,27 @@ func (articleTask *Task) ImportArticle(fileName string) (*Article, error) {
 	}
 
 	importfile := new(HugoArticle)
-	err = frontmatter.Unmarshal(artfile, importfile)
+	importfile.Content, _, err = unmarshalFrontMatter(artfile, importfile)
 	if err != nil {
 		msg := fmt.Errorf("Error unmarshaling yaml file: %s", err.Error())
 		return nil, msg
 	}
 
+	if importfile.Date != "" {
+		article.PublishDate, err = parseDate(importfile.Date, articleTask.location())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date in %s: %s", fileName, err.Error())
+		}
+	}
+
 	articleurl := GetFileName(importfile.URL, "/")
 
 	articlepath := filepath.Dir(fileName)
 	newarticlepath := articlepath + "/" + articleurl
 
-	if _, err := os.Stat(newarticlepath); os.IsNotExist(err) {
+	if _, err := os.Stat(newarticlepath); os.IsNotExist(err) && articleTask.dryRun() {
+		articleTask.recordFile("create directory", newarticlepath)
+	} else if os.IsNotExist(err) {
 		err = os.Mkdir(newarticlepath, 0755)
 		if err != nil {
 			msg := fmt.Errorf("Error creating directory: %s /\n ", err.Error())
@@ -230,14 +272,17 @@ func (articleTask *Task) ImportArticle(fileName string) (*Article, error) {
 		}
 	}
 
-	importPublishDate, err := time.Parse("2006-01-02", importfile.Date)
-	if err == nil {
-		article.PublishDate = importPublishDate
+	article.Status = StatusPublished
+	if importfile.Draft {
+		article.Status = StatusDraft
 	}
 
 	article.Title = importfile.Title
 	article.URL = articleurl + ".md"
 	article.Author = importfile.Author
+	if article.Author == "" {
+		article.Author = articleTask.settings.Defaults.Author
+	}
 
 	if importfile.Banner != "" {
 		article.Banner = GetFileName(importfile.Banner, "/")
@@ -247,7 +292,7 @@ func (articleTask *Task) ImportArticle(fileName string) (*Article, error) {
 		article.Images = []string{article.Banner}
 	}
 
-	article.DataSource = newarticlepath + "/" + article.URL
+	article.DataSource = articleTask.dataSource(newarticlepath + "/" + article.URL)
 
 	for _, cat := range importfile.Categories {
 		newcat := strings.ToLower(cat)
@@ -261,10 +306,17 @@ func (articleTask *Task) ImportArticle(fileName string) (*Article, error) {
 
 	article.Content = importfile.Content
 
-	articleTask.saveMarkdownFile(*article)
+	if err := articleTask.saveMarkdownFile(*article); err != nil {
+		return nil, err
+	}
+
+	if articleTask.dryRun() {
+		articleTask.recordFile("remove file", fileName)
+		return article, nil
+	}
 
 	if _, err := os.Stat(fileName); err == nil {
-		fmt.Printf("Removing file: %s \n", fileName)
+		articleTask.printf("Removing file: %s \n", fileName)
 		err = os.Remove(fileName)
 		if err != nil {
 			msg := fmt.Errorf("Error Deleting import yaml file: %s \n ", err.Error())
@@ -276,111 +328,265 @@ func (articleTask *Task) ImportArticle(fileName string) (*Article, error) {
 }
 
 //SaveArticle saves input data as an article and backups to a local md file
-func (articleTask *Task) SaveArticle(article *Article, bypassquestions bool) (*Article, error) {
-	if articleTask.service.Username == "" {
-		articleTask.service.Username = AskForStringValue("Username", "", true)
+func (articleTask *Task) SaveArticle(ctx context.Context, article *Article, bypassquestions bool) (*Article, error) {
+	if err := articleTask.askArticleQuestions(article, bypassquestions); err != nil {
+		return article, err
 	}
 
-	if articleTask.service.Password == "" {
-		articleTask.service.Password = AskForHiddenStringValue("Password", "", true)
+	if article.ID != "" {
+		_, err := articleTask.store.GetArticle(ctx, article.ID)
+		if err == ErrNotFound {
+			article.ID = ""
+		} else if err != nil {
+			return article, err
+		}
 	}
 
-	if articleTask.service.ServiceURL == "" {
-		articleTask.service.ServiceURL = AskForStringValue("Service Url", "", true)
+	articleTask.addContentImages(article)
+	article.ImageVariants = articleTask.imageVariants(article)
+
+	var err error
+	if article.ID == "" {
+		err = articleTask.store.CreateArticle(ctx, article)
+	} else {
+		err = articleTask.store.UpdateArticle(ctx, article)
 	}
 
-	if articleTask.service.AuthKey == "" {
-		log.Fatal("AuthKey environment variable must be set.")
+	if err != nil {
+		articleTask.printf("Unable to Save File, %s \n", err.Error())
+		return article, err
 	}
 
-	if article.Title == "" || bypassquestions == false {
-		article.Title = AskForStringValue("Article Title", article.Title, true)
+	uploadErr := articleTask.uploadImages(ctx, article)
+
+	// image links are only pointed at the service once the images are there
+	if rewritten := articleTask.withImageURLs(article); uploadErr == nil && rewritten != article {
+		if err := articleTask.store.UpdateArticle(ctx, rewritten); err != nil {
+			articleTask.printf("Unable to Save File, %s \n", err.Error())
+			return article, err
+		}
 	}
 
-	if bypassquestions == false {
-		article.PublishDate = AskForDateValue("Publish Date", article.PublishDate)
+	// the file is written even when images failed, so a created article keeps its id
+	if err := articleTask.saveMarkdownFile(*article); err != nil {
+		return article, err
 	}
 
-	if article.URL == "" || bypassquestions == false {
-		article.URL = AskForStringValue("Permalink", article.URL, true)
+	return article, uploadErr
+}
+
+//uploadImages uploads the article's images that aren't on the service yet or have changed since
+//they were uploaded, sharing the task's upload pool when it runs in a batch. Images missing on
+//disk are skipped, any other image that couldn't be uploaded is an error.
+func (articleTask *Task) uploadImages(ctx context.Context, article *Article) error {
+	articleFile := articleTask.localPath(article.DataSource)
+
+	var failedLock sync.Mutex
+	var failed []string
+	fail := func(filename string) {
+		failedLock.Lock()
+		failed = append(failed, filename)
+		failedLock.Unlock()
 	}
 
-	if bypassquestions == false {
-		article.Banner = AskForStringValue("Banner Image FileName", article.Banner, false)
+	var uploads sync.WaitGroup
+	for _, imageFilePath := range article.Images {
+		imagepath := resolveImage(articleTask.settings.ContentRoot, articleFile, imageFilePath)
+		strfile := strings.Split(imageFilePath, "/")
+		filename := strfile[len(strfile)-1]
+
+		upload := func() {
+			hash, err := fileHash(imagepath)
+			if err != nil {
+				articleTask.printf("Could not read image %v, %v \n", filename, err.Error())
+				return
+			}
+
+			uploaded, err := articleTask.imageUploaded(ctx, article.ID, filename, imagepath, hash)
+			if err != nil {
+				articleTask.printf("Could not check image %v, %v \n", filename, err.Error())
+				fail(filename)
+				return
+			}
+
+			if uploaded {
+				return
+			}
+
+			paths, cleanup, err := articleTask.prepareImage(imagepath)
+			if err != nil {
+				articleTask.printf("Could not process image %v, %v \n", filename, err.Error())
+				fail(filename)
+				return
+			}
+
+			defer cleanup()
+			for _, path := range paths {
+				err := articleTask.store.UploadImage(ctx, article.ID, path)
+				if err != nil {
+					articleTask.printf("Could not save images %v, please try again. %v \n", filepath.Base(path), err.Error())
+					fail(filename)
+					return
+				}
+			}
+
+			articleTask.images.put(article.ID, filename, hash)
+		}
+
+		if articleTask.uploads == nil {
+			upload()
+			continue
+		}
+
+		uploads.Add(1)
+		articleTask.uploads <- struct{}{}
+		go func() {
+			defer func() {
+				<-articleTask.uploads
+				uploads.Done()
+			}()
+
+			upload()
+		}()
 	}
 
-	if article.Author == "" || bypassquestions == false {
-		article.Author = AskForStringValue("Author Name", article.Author, true)
+	uploads.Wait()
+
+	if !articleTask.dryRun() {
+		if err := articleTask.images.save(); err != nil {
+			articleTask.printf("Could not save image cache, %v \n", err.Error())
+		}
 	}
 
-	if bypassquestions == false {
-		article.Categories = AskForCSV("Categories (csv)", article.Categories)
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("Could not upload images %s", strings.Join(failed, ", "))
 	}
 
-	if bypassquestions == false {
-		article.Tags = AskForCSV("Tags (csv)", article.Tags)
+	return nil
+}
+
+//imageUploaded checks whether the service already has the image as it is now. The local cache
+//answers without a request; otherwise the service's hash of the image is compared when it has one.
+func (articleTask *Task) imageUploaded(ctx context.Context, articleID, filename, imagepath, hash string) (bool, error) {
+	cached := articleTask.images.get(articleID, filename)
+	if cached == hash {
+		return true, nil
 	}
 
-	requestMethod := "POST"
-	requestURL := "articles"
+	remoteHash, exists, err := articleTask.store.ImageHash(ctx, articleID, filename)
+	if err != nil || !exists {
+		return false, err
+	}
 
-	if article.ID != "" {
+	// a processed image is uploaded with a different hash than the local file
+	if remoteHash != "" && !(articleTask.settings.Images.Process && processableImage(imagepath)) {
+		if remoteHash == hash {
+			articleTask.images.put(articleID, filename, hash)
+			return true, nil
+		}
 
-		requestMethod = "PUT"
-		requestURL = "articles/" + article.ID
-		_, err := articleTask.GetArticle(article.ID)
+		return false, nil
+	}
 
-		if err != nil {
-			requestMethod = "POST"
-			article.ID = ""
+	// without a hash to compare, an image uploaded before there was a cache is kept,
+	// one that has changed since it was cached is uploaded again
+	if cached == "" {
+		articleTask.images.put(articleID, filename, hash)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+//askArticleQuestions prompts for the article fields, only asking for missing required values when bypassing questions
+func (articleTask *Task) askArticleQuestions(article *Article, bypassquestions bool) error {
+	var err error
+	prompter := articleTask.prompter
+
+	if article.Title == "" || bypassquestions == false {
+		if article.Title, err = prompter.String("Article Title", article.Title, true); err != nil {
+			return err
 		}
 	}
 
-	err := articleTask.service.SendRequest(requestMethod, requestURL, article)
+	if bypassquestions == false {
+		if article.PublishDate, err = prompter.Date("Publish Date", article.PublishDate.In(articleTask.location())); err != nil {
+			return err
+		}
+	}
 
-	if err != nil {
-		fmt.Printf("Unable to Save File, %s \n", err.Error())
-		return article, err
+	if article.URL == "" || bypassquestions == false {
+		if article.URL, err = prompter.String("Permalink", article.URL, true); err != nil {
+			return err
+		}
 	}
 
-	imageEndPoint := fmt.Sprintf("images/%v", article.ID)
-	datasourcePath := filepath.Dir(article.DataSource)
+	if bypassquestions == false {
+		if article.Banner, err = prompter.String("Banner Image FileName", article.Banner, false); err != nil {
+			return err
+		}
+	}
 
-	for _, imageFilePath := range article.Images {
-		imagepath := datasourcePath + "/" + imageFilePath
-		strfile := strings.Split(imageFilePath, "/")
-		filename := strfile[len(strfile)-1]
+	if article.Author == "" || bypassquestions == false {
+		if article.Author, err = prompter.String("Author Name", article.Author, true); err != nil {
+			return err
+		}
+	}
 
-		imageLink := articleTask.service.ServiceURL + "/" + imageEndPoint + "/" + filename
-		if !articleTask.service.ResolveLink(imageLink) {
-			_, err := articleTask.service.Upload(imageEndPoint, imagepath)
-			if err != nil {
-				fmt.Printf("Could not save images %v, please try again. %v \n", imageLink, err.Error())
-				continue
-			}
+	if bypassquestions == false {
+		if article.Categories, err = prompter.CSV("Categories (csv)", article.Categories); err != nil {
+			return err
 		}
 	}
 
-	articleTask.saveMarkdownFile(*article)
+	if bypassquestions == false {
+		if article.Tags, err = prompter.CSV("Tags (csv)", article.Tags); err != nil {
+			return err
+		}
+	}
 
-	return article, err
+	return nil
+}
+
+//UpdateOptions controls which articles UpdateArticles sends
+type UpdateOptions struct {
+	BatchOptions
+
+	//All sends every article, not just those changed since they were last sent
+	All bool
+	//IncludeDrafts sends draft articles too
+	IncludeDrafts bool
 }
 
 //UpdateArticles updates and articles in a folder
-func (articleTask *Task) UpdateArticles(filedir string, bypassQuestions bool) error {
+func (articleTask *Task) UpdateArticles(ctx context.Context, filedir string, bypassQuestions bool, options UpdateOptions) (*BatchSummary, error) {
 	if filedir == "" {
-		filedir = AskForStringValue("Import File or Folder", "", false)
+		filedir = articleTask.settings.ContentRoot
 	}
 
-	subDirToSkip := []string{".git", ".DS_Store"}
-	err := filepath.Walk(filedir, func(path string, info os.FileInfo, err error) error {
+	var err error
+	if filedir == "" {
+		if filedir, err = articleTask.prompter.String("Import File or Folder", filedir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := articleTask.openState(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	subDirToSkip := []string{".git", ".DS_Store", stateDir}
+	err = filepath.Walk(filedir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			fmt.Printf("prevent panic by handling failure accessing a path %q: %v\n", filedir, err)
+			articleTask.printf("prevent panic by handling failure accessing a path %q: %v\n", filedir, err)
 			return err
 		}
 
 		if info.IsDir() && contains(subDirToSkip, info.Name()) {
-			fmt.Printf("skipping a dir without errors: %+v \n", info.Name())
+			articleTask.printf("skipping a dir without errors: %+v \n", info.Name())
 			return filepath.SkipDir
 		}
 
@@ -389,12 +595,7 @@ func (articleTask *Task) UpdateArticles(filedir string, bypassQuestions bool) er
 			extension := filepath.Ext(filename)
 
 			if extension == ".md" {
-				fmt.Printf("updating file: %s \n ", path)
-
-				_, err := articleTask.LoadArticle(path, bypassQuestions)
-				if err != nil {
-					return err
-				}
+				paths = append(paths, path)
 			}
 		}
 
@@ -402,14 +603,93 @@ func (articleTask *Task) UpdateArticles(filedir string, bypassQuestions bool) er
 	})
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	batchOptions := options.BatchOptions
+	if !bypassQuestions {
+		// questions for several articles at once can't share the terminal
+		batchOptions.Concurrency = 1
+	}
+
+	summary := articleTask.runBatch(ctx, paths, batchOptions, func(ctx context.Context, jobTask *Task, path string) BatchResult {
+		return jobTask.updateArticle(ctx, state, path, bypassQuestions, options)
+	})
+
+	err = summary.err(ctx)
+	if saveErr := state.save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+
+	return summary, err
+}
+
+//updateArticle sends one article file, skipping it when it hasn't changed since it was last sent
+func (articleTask *Task) updateArticle(ctx context.Context, state *syncState, path string, bypassQuestions bool, options UpdateOptions) BatchResult {
+	existing, err := articleTask.readArticleFile(path)
+	if err != nil {
+		articleTask.printf("error: %s \n ", err.Error())
+		return BatchResult{Status: BatchFailed, Err: err}
+	}
+
+	if existing.Status == StatusDraft && !options.IncludeDrafts {
+		articleTask.printf("skipping draft: %s \n ", path)
+		return BatchResult{ID: existing.ID, Status: BatchSkipped, Reason: "draft"}
+	}
+
+	if !options.All && state.unchanged(path, existing) {
+		articleTask.printf("skipping unchanged file: %s \n ", path)
+		return BatchResult{ID: existing.ID, Status: BatchSkipped, Reason: "unchanged"}
+	}
+
+	articleTask.printf("updating file: %s \n ", path)
+
+	article, err := articleTask.LoadArticle(ctx, path, bypassQuestions)
+	if err != nil {
+		articleTask.printf("error: %s \n ", err.Error())
+		return BatchResult{ID: existing.ID, Status: BatchFailed, Err: err}
+	}
+
+	state.record(path, article)
+
+	// SaveArticle gives the article a new id when it wasn't on the service
+	status := BatchUpdated
+	if existing.ID != article.ID {
+		status = BatchCreated
+	}
+
+	return BatchResult{ID: article.ID, Status: status}
+}
+
+//extraFields returns the front matter fields that aren't ImportArticle fields
+func extraFields(fields map[string]interface{}) map[string]interface{} {
+	known := yamlKeys(reflect.TypeOf(ImportArticle{}))
+
+	extra := make(map[string]interface{})
+	for key, value := range fields {
+		if !known[key] {
+			extra[key] = value
+		}
+	}
+
+	if len(extra) == 0 {
+		return nil
+	}
+
+	return extra
 }
 
 func (articleTask *Task) saveMarkdownFile(article Article) error {
-	filelocation := article.DataSource
+	filelocation := articleTask.localPath(article.DataSource)
+
+	// keep the fields, order and comments of the file being replaced
+	existing, _ := ioutil.ReadFile(filelocation)
+
+	// published is the default, so it is only written to a file that already sets a status
+	status := article.Status
+	if status == StatusPublished && !hasField(existing, "status") {
+		status = ""
+	}
 
 	var importfile = &ImportArticle{
 		article.ID,
@@ -417,7 +697,8 @@ func (articleTask *Task) saveMarkdownFile(article Article) error {
 		article.URL,
 		article.Images,
 		article.Banner,
-		article.PublishDate.Format("01/02/2006"),
+		formatDate(article.PublishDate, articleTask.location()),
+		status,
 		article.DataSource,
 		article.Author,
 		article.Categories,
@@ -425,15 +706,20 @@ func (articleTask *Task) saveMarkdownFile(article Article) error {
 		article.Content,
 	}
 
-	data, err := frontmatter.Marshal(importfile)
+	data, err := marshalFrontMatter(existing, importfile, article.Extra, article.Content)
 	if err != nil {
-		fmt.Printf("err! %s", err.Error())
+		return fmt.Errorf("Error marshaling yaml file: %s", err.Error())
+	}
+
+	if articleTask.dryRun() {
+		articleTask.recordFile("write file", filelocation)
+		return nil
 	}
 
 	err = ioutil.WriteFile(filelocation, data, 0644)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("Error saving markdown file: %s", err.Error())
 	}
 
-	return err
+	return nil
 }
@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+//ErrNotFound is returned by an ArticleStore when the requested item does not exist
+var ErrNotFound = errors.New("not found")
+
+//ArticleStore is the backend articles, links and images are published to
+type ArticleStore interface {
+	//ListArticles returns every article
+	ListArticles(ctx context.Context) ([]*Article, error)
+	//GetArticle returns the article with the given id, or ErrNotFound
+	GetArticle(ctx context.Context, id string) (*Article, error)
+	//CreateArticle stores a new article and sets its ID
+	CreateArticle(ctx context.Context, article *Article) error
+	//UpdateArticle replaces an existing article
+	UpdateArticle(ctx context.Context, article *Article) error
+	//DeleteArticle removes an article
+	DeleteArticle(ctx context.Context, id string) error
+
+	//SaveLink creates the link, or updates it when it already has an ID
+	SaveLink(ctx context.Context, link *Link) error
+	//DeleteLink removes a link
+	DeleteLink(ctx context.Context, id string) error
+
+	//UploadImage stores the image file at path against an article
+	UploadImage(ctx context.Context, articleID, path string) error
+	//DownloadImage writes an article's image to w
+	DownloadImage(ctx context.Context, articleID, filename string, w io.Writer) error
+	//ImageHash returns the hex SHA-256 of an article's image, empty when the store can't tell,
+	//and whether the article has an image with the filename
+	ImageHash(ctx context.Context, articleID, filename string) (hash string, exists bool, err error)
+	//ImageURL returns the address an uploaded image is served from
+	ImageURL(articleID, filename string) string
+}
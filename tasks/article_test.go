@@ -0,0 +1,204 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evcraddock/article-importer/config"
+)
+
+//failingUploadStore is a MemoryStore whose image uploads fail
+type failingUploadStore struct {
+	*MemoryStore
+}
+
+func (store failingUploadStore) UploadImage(ctx context.Context, articleID, path string) error {
+	return errors.New("upload failed")
+}
+
+func writeArticleFile(t *testing.T, dir string) string {
+	articlePath := filepath.Join(dir, "post", "post.md")
+	if err := os.MkdirAll(filepath.Dir(articlePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := "---\ntitle: Post\nurl: post.md\npublishDate: 2026-10-18\ndataSource: post/post.md\nauthor: Erik\n---\n![a](a.png)\n"
+	if err := ioutil.WriteFile(articlePath, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "post", "a.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return articlePath
+}
+
+func TestSaveArticle(t *testing.T) {
+	tests := []struct {
+		name        string
+		store       func(*MemoryStore) ArticleStore
+		update      bool
+		dryRun      bool
+		wantErr     string
+		wantContent string
+		wantImage   bool
+	}{
+		{
+			name:        "uploaded",
+			store:       func(store *MemoryStore) ArticleStore { return store },
+			wantContent: "![a](images/{id}/a.png)\n",
+			wantImage:   true,
+		},
+		{
+			name:        "upload failed",
+			store:       func(store *MemoryStore) ArticleStore { return failingUploadStore{store} },
+			wantErr:     "Could not upload images a.png",
+			wantContent: "![a](a.png)\n",
+		},
+		{
+			name:        "updated",
+			store:       func(store *MemoryStore) ArticleStore { return store },
+			update:      true,
+			wantContent: "![a](images/{id}/a.png)\n",
+			wantImage:   true,
+		},
+		{
+			// the service keeps the local links until the images are there
+			name:        "update upload failed",
+			store:       func(store *MemoryStore) ArticleStore { return failingUploadStore{store} },
+			update:      true,
+			wantErr:     "Could not upload images a.png",
+			wantContent: "![a](a.png)\n",
+		},
+		{
+			name:   "dry run",
+			store:  func(store *MemoryStore) ArticleStore { return store },
+			dryRun: true,
+		},
+	}
+
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "articles-")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer os.RemoveAll(dir)
+
+		articlePath := writeArticleFile(t, dir)
+		before, _ := ioutil.ReadFile(articlePath)
+
+		memory := NewMemoryStore()
+		settings := &config.Settings{ContentRoot: dir, RewriteImages: true, DryRun: test.dryRun}
+		task := NewTaskWithStore(settings, test.store(memory), &NonInteractivePrompter{})
+		task.out = ioutil.Discard
+
+		article, err := task.readArticleFile(articlePath)
+		if err != nil {
+			t.Fatalf("%s: readArticleFile failed: %s", test.name, err)
+		}
+
+		if test.update {
+			stored := &Article{Title: "Old", Content: "Old"}
+			if err := memory.CreateArticle(context.Background(), stored); err != nil {
+				t.Fatal(err)
+			}
+
+			article.ID = stored.ID
+		}
+
+		_, err = task.SaveArticle(context.Background(), article, true)
+		if test.wantErr == "" && err != nil {
+			t.Errorf("%s: SaveArticle failed: %s", test.name, err)
+		} else if test.wantErr != "" && (err == nil || err.Error() != test.wantErr) {
+			t.Errorf("%s: SaveArticle returned %v, want %s", test.name, err, test.wantErr)
+		}
+
+		after, _ := ioutil.ReadFile(articlePath)
+		saved := memory.Articles()
+
+		if test.dryRun {
+			if len(saved) != 0 || string(after) != string(before) {
+				t.Errorf("%s: SaveArticle saved %v and wrote\n%s", test.name, saved, after)
+			}
+
+			if operations := task.recorder.Operations(); len(operations) == 0 {
+				t.Errorf("%s: SaveArticle recorded no operations", test.name)
+			}
+
+			continue
+		}
+
+		if len(saved) != 1 {
+			t.Errorf("%s: SaveArticle saved %d articles", test.name, len(saved))
+			continue
+		}
+
+		id := saved[0].ID
+		if want := strings.Replace(test.wantContent, "{id}", id, 1); saved[0].Content != want {
+			t.Errorf("%s: SaveArticle saved content %q, want %q", test.name, saved[0].Content, want)
+		}
+
+		// the file keeps the id even when the images have to be uploaded again
+		if !strings.Contains(string(after), "id: \""+id+"\"") {
+			t.Errorf("%s: SaveArticle wrote\n%s\nwithout the id", test.name, after)
+		}
+
+		var image strings.Builder
+		hasImage := memory.DownloadImage(context.Background(), id, "a.png", &image) == nil
+		if hasImage != test.wantImage {
+			t.Errorf("%s: image uploaded = %v, want %v", test.name, hasImage, test.wantImage)
+		}
+	}
+}
+
+func TestSaveMarkdownFileStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "articles-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	articlePath := writeArticleFile(t, dir)
+	task := NewTaskWithStore(&config.Settings{ContentRoot: dir}, NewMemoryStore(), &NonInteractivePrompter{})
+
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{StatusPublished, ""},
+		{StatusDraft, "status: draft\n"},
+		// a file that sets a status keeps it
+		{StatusPublished, "status: published\n"},
+	}
+
+	for _, test := range tests {
+		article := &Article{
+			Title:       "Post",
+			URL:         "post.md",
+			PublishDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+			Status:      test.status,
+			DataSource:  "post/post.md",
+			Author:      "Erik",
+		}
+
+		if err := task.saveMarkdownFile(*article); err != nil {
+			t.Fatalf("saveMarkdownFile failed: %s", err)
+		}
+
+		data, _ := ioutil.ReadFile(articlePath)
+		if test.want == "" && strings.Contains(string(data), "status:") {
+			t.Errorf("saveMarkdownFile(%s) wrote\n%s\nwith a status", test.status, data)
+		} else if test.want != "" && !strings.Contains(string(data), test.want) {
+			t.Errorf("saveMarkdownFile(%s) wrote\n%s\nwithout %q", test.status, data, test.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+//BatchStatus is the outcome of one file in a batch run
+type BatchStatus string
+
+//Batch statuses
+const (
+	BatchCreated  BatchStatus = "created"
+	BatchUpdated  BatchStatus = "updated"
+	BatchImported BatchStatus = "imported"
+	BatchSkipped  BatchStatus = "skipped"
+	BatchFailed   BatchStatus = "failed"
+)
+
+//BatchOptions controls how a batch of files is processed
+type BatchOptions struct {
+	//Concurrency is the number of files processed at once
+	Concurrency int
+	//KeepGoing attempts every file instead of stopping at the first failure
+	KeepGoing bool
+}
+
+//BatchResult is the outcome of one file in a batch run
+type BatchResult struct {
+	Path   string
+	ID     string
+	Status BatchStatus
+	Err    error
+	//Reason explains a skipped file
+	Reason string
+}
+
+//BatchSummary collects the results of a batch run in file order
+type BatchSummary struct {
+	Results  []BatchResult
+	Duration time.Duration
+}
+
+//Count returns the number of results with the status
+func (summary *BatchSummary) Count(status BatchStatus) int {
+	count := 0
+	for _, result := range summary.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+//WriteTable writes one line per file followed by the totals
+func (summary *BatchSummary) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "STATUS\tFILE\tID\tREASON\n")
+	for _, result := range summary.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Status, result.Path, result.ID, result.reason())
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d files: %d created, %d updated, %d imported, %d skipped, %d failed in %v\n", len(summary.Results),
+		summary.Count(BatchCreated), summary.Count(BatchUpdated), summary.Count(BatchImported),
+		summary.Count(BatchSkipped), summary.Count(BatchFailed), summary.Duration.Round(time.Millisecond))
+	return err
+}
+
+//WriteJSON writes the results and totals as a JSON document
+func (summary *BatchSummary) WriteJSON(w io.Writer) error {
+	type jsonResult struct {
+		Path   string      `json:"path"`
+		ID     string      `json:"id,omitempty"`
+		Status BatchStatus `json:"status"`
+		Reason string      `json:"reason,omitempty"`
+	}
+
+	report := struct {
+		Results  []jsonResult        `json:"results"`
+		Totals   map[BatchStatus]int `json:"totals"`
+		Duration string              `json:"duration"`
+	}{
+		Results:  make([]jsonResult, 0, len(summary.Results)),
+		Totals:   make(map[BatchStatus]int),
+		Duration: summary.Duration.String(),
+	}
+
+	for _, result := range summary.Results {
+		report.Results = append(report.Results, jsonResult{result.Path, result.ID, result.Status, result.reason()})
+		report.Totals[result.Status]++
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func (result BatchResult) reason() string {
+	if result.Err == nil {
+		return result.Reason
+	}
+
+	return result.Err.Error()
+}
+
+//err returns the failure of the run, or the context error when the run was cancelled
+func (summary *BatchSummary) err(ctx context.Context) error {
+	var failures []BatchResult
+	for _, result := range summary.Results {
+		if result.Status == BatchFailed {
+			failures = append(failures, result)
+		}
+	}
+
+	if len(failures) == 1 {
+		return fmt.Errorf("%s: %w", failures[0].Path, failures[0].Err)
+	}
+
+	if len(failures) > 1 {
+		return fmt.Errorf("%d of %d files failed", len(failures), len(summary.Results))
+	}
+
+	return ctx.Err()
+}
+
+//batchJob processes one file, writing its output through jobTask
+type batchJob func(ctx context.Context, jobTask *Task, path string) BatchResult
+
+//runBatch runs job for each path in a pool of concurrency workers. Each job's output is
+//buffered and printed in path order. Unless keeping going, no new jobs are started after one fails.
+func (task *Task) runBatch(ctx context.Context, paths []string, options BatchOptions, job batchJob) *BatchSummary {
+	start := time.Now()
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(paths))
+	outputs := make([]bytes.Buffer, len(paths))
+	done := make([]chan struct{}, len(paths))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var failedLock sync.Mutex
+	failed := false
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range paths {
+			failedLock.Lock()
+			stop := failed
+			failedLock.Unlock()
+
+			if stop || ctx.Err() != nil {
+				// jobs that never ran are left out of the summary
+				for ; i < len(paths); i++ {
+					close(done[i])
+				}
+
+				return
+			}
+
+			jobs <- i
+		}
+	}()
+
+	// image uploads of all workers share one pool the same size as the article pool
+	uploads := make(chan struct{}, concurrency)
+
+	var workers sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range jobs {
+				// a job handed over while another was failing is left out like the ones after it
+				failedLock.Lock()
+				stop := failed
+				failedLock.Unlock()
+
+				if stop || ctx.Err() != nil {
+					close(done[i])
+					continue
+				}
+
+				jobTask := *task
+				jobTask.out = &lockedWriter{w: &outputs[i]}
+				jobTask.uploads = uploads
+
+				result := job(ctx, &jobTask, paths[i])
+				result.Path = paths[i]
+				results[i] = result
+
+				if result.Status == BatchFailed && !options.KeepGoing {
+					failedLock.Lock()
+					failed = true
+					failedLock.Unlock()
+				}
+
+				close(done[i])
+			}
+		}()
+	}
+
+	summary := &BatchSummary{}
+	for i := range paths {
+		<-done[i]
+		task.out.Write(outputs[i].Bytes())
+		if results[i].Status != "" {
+			summary.Results = append(summary.Results, results[i])
+		}
+	}
+
+	workers.Wait()
+	summary.Duration = time.Since(start)
+
+	return summary
+}
+
+//lockedWriter lets the image uploads of one article write to the same output
+type lockedWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (writer *lockedWriter) Write(p []byte) (int, error) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	return writer.w.Write(p)
+}
@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evcraddock/article-importer/config"
+)
+
+func TestRunBatch(t *testing.T) {
+	paths := []string{"a.md", "b.md", "c.md", "d.md", "e.md"}
+
+	tests := []struct {
+		name        string
+		options     BatchOptions
+		fail        string
+		wantPaths   []string
+		wantFailed  int
+		wantErr     string
+		wantOutputs string
+	}{
+		{
+			name:        "in order",
+			options:     BatchOptions{Concurrency: 3},
+			wantPaths:   paths,
+			wantOutputs: "a.md\nb.md\nc.md\nd.md\ne.md\n",
+		},
+		{
+			name:        "stop on failure",
+			options:     BatchOptions{Concurrency: 1},
+			fail:        "b.md",
+			wantPaths:   []string{"a.md", "b.md"},
+			wantFailed:  1,
+			wantErr:     "b.md: failed",
+			wantOutputs: "a.md\nb.md\n",
+		},
+		{
+			name:        "keep going",
+			options:     BatchOptions{Concurrency: 2, KeepGoing: true},
+			fail:        "b.md",
+			wantPaths:   paths,
+			wantFailed:  1,
+			wantErr:     "b.md: failed",
+			wantOutputs: "a.md\nb.md\nc.md\nd.md\ne.md\n",
+		},
+		{
+			name:        "several failures",
+			options:     BatchOptions{Concurrency: 0, KeepGoing: true},
+			fail:        "b.md d.md",
+			wantPaths:   paths,
+			wantFailed:  2,
+			wantErr:     "2 of 5 files failed",
+			wantOutputs: "a.md\nb.md\nc.md\nd.md\ne.md\n",
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		task := NewTaskWithStore(&config.Settings{}, NewMemoryStore(), &NonInteractivePrompter{})
+		task.out = &out
+
+		summary := task.runBatch(context.Background(), paths, test.options, func(ctx context.Context, jobTask *Task, path string) BatchResult {
+			// later files finish first, so the output has to be put back in order
+			time.Sleep(time.Duration(len(paths)-strings.Index("abcde", path[:1])) * time.Millisecond)
+			jobTask.printf("%s\n", path)
+
+			if strings.Contains(test.fail, path) {
+				return BatchResult{Status: BatchFailed, Err: errors.New("failed")}
+			}
+
+			return BatchResult{ID: path[:1], Status: BatchCreated}
+		})
+
+		var gotPaths []string
+		for _, result := range summary.Results {
+			gotPaths = append(gotPaths, result.Path)
+		}
+
+		if !reflect.DeepEqual(gotPaths, test.wantPaths) {
+			t.Errorf("%s: runBatch results = %v, want %v", test.name, gotPaths, test.wantPaths)
+		}
+
+		if got := summary.Count(BatchFailed); got != test.wantFailed {
+			t.Errorf("%s: runBatch failed %d, want %d", test.name, got, test.wantFailed)
+		}
+
+		if got := out.String(); got != test.wantOutputs {
+			t.Errorf("%s: runBatch printed %q, want %q", test.name, got, test.wantOutputs)
+		}
+
+		err := summary.err(context.Background())
+		if got := fmt.Sprint(err); (err != nil || test.wantErr != "") && got != test.wantErr {
+			t.Errorf("%s: err = %s, want %s", test.name, got, test.wantErr)
+		}
+	}
+}
+
+func TestRunBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	task := NewTaskWithStore(&config.Settings{}, NewMemoryStore(), &NonInteractivePrompter{})
+
+	summary := task.runBatch(ctx, []string{"a.md", "b.md", "c.md"}, BatchOptions{Concurrency: 1}, func(ctx context.Context, jobTask *Task, path string) BatchResult {
+		cancel()
+		return BatchResult{Status: BatchCreated}
+	})
+
+	if len(summary.Results) != 1 || summary.Results[0].Path != "a.md" {
+		t.Errorf("runBatch results = %+v, want only a.md", summary.Results)
+	}
+
+	if err := summary.err(ctx); err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,121 @@
+package tasks
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+//imageReferences match the image paths in markdown content, the path is the first group of each
+var imageReferences = []*regexp.Regexp{
+	// ![alt](path "title")
+	regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+["'][^)]*["'])?\s*\)`),
+	// <img src="path">
+	regexp.MustCompile(`(?i)<img\s[^>]*?src\s*=\s*["']([^"']+)["']`),
+	// {{< figure src="path" >}} and {{% figure src="path" %}}
+	regexp.MustCompile(`\{\{[<%]\s*figure\s[^}]*?src\s*=\s*["']?([^"'\s}]+)["']?`),
+}
+
+//contentImages returns the local image paths referenced in markdown content, in the order they appear
+func contentImages(content string) []string {
+	var images []string
+	for _, reference := range imageReferences {
+		for _, match := range reference.FindAllStringSubmatch(content, -1) {
+			if image, ok := localImage(match[1]); ok && !contains(images, image) {
+				images = append(images, image)
+			}
+		}
+	}
+
+	return images
+}
+
+//localImage cleans an image reference, returning false for anything that isn't a path relative to the article
+func localImage(reference string) (string, bool) {
+	if reference == "" || strings.HasPrefix(reference, "/") || strings.HasPrefix(reference, "#") ||
+		strings.Contains(reference, "://") || strings.HasPrefix(reference, "data:") {
+		return "", false
+	}
+
+	if i := strings.IndexAny(reference, "?#"); i >= 0 {
+		reference = reference[:i]
+	}
+
+	reference = path.Clean(reference)
+	if strings.HasPrefix(reference, "../") {
+		return "", false
+	}
+
+	return reference, true
+}
+
+//addContentImages merges the images referenced in the article's content that exist next to the
+//article file into its Images
+func (articleTask *Task) addContentImages(article *Article) {
+	articleFile := articleTask.localPath(article.DataSource)
+
+	for _, image := range contentImages(article.Content) {
+		if containsImage(article.Images, image) {
+			continue
+		}
+
+		if !fileExists(resolveImage(articleTask.settings.ContentRoot, articleFile, image)) {
+			continue
+		}
+
+		articleTask.printf("found image %s in content \n", image)
+		article.Images = append(article.Images, image)
+	}
+}
+
+//withImageURLs returns a copy of the article with the local images in its content pointing at
+//the service, or the article itself when images aren't rewritten or there is nothing to rewrite
+func (articleTask *Task) withImageURLs(article *Article) *Article {
+	if !articleTask.settings.RewriteImages || article.ID == "" {
+		return article
+	}
+
+	content := article.Content
+	for _, reference := range imageReferences {
+		content = replaceGroup(reference, content, func(match string) string {
+			image, ok := localImage(match)
+			if !ok || !containsImage(article.Images, image) {
+				return match
+			}
+
+			return articleTask.store.ImageURL(article.ID, path.Base(image))
+		})
+	}
+
+	if content == article.Content {
+		return article
+	}
+
+	rewritten := *article
+	rewritten.Content = content
+	return &rewritten
+}
+
+//replaceGroup replaces the first group of each match of re in s
+func replaceGroup(re *regexp.Regexp, s string, replace func(string) string) string {
+	var result strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(s, -1) {
+		result.WriteString(s[last:match[2]])
+		result.WriteString(replace(s[match[2]:match[3]]))
+		last = match[3]
+	}
+
+	result.WriteString(s[last:])
+	return result.String()
+}
+
+//containsImage checks for an image path, ignoring differences like a leading ./
+func containsImage(images []string, image string) bool {
+	for _, existing := range images {
+		if path.Clean(existing) == image {
+			return true
+		}
+	}
+	return false
+}
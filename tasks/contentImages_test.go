@@ -0,0 +1,106 @@
+package tasks
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/evcraddock/article-importer/config"
+)
+
+func TestContentImages(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"no images", nil},
+		{"![a](a.png) and ![b](./img/b.jpg \"B\")", []string{"a.png", "img/b.jpg"}},
+		{"![a](<a b.png>)", nil},
+		{"![a](a.png?v=2#top) ![again](./a.png)", []string{"a.png"}},
+		{`<img class="x" SRC='photo.jpg'>`, []string{"photo.jpg"}},
+		{`{{< figure src="fig.png" title="Fig" >}} {{% figure src=other.png %}}`, []string{"fig.png", "other.png"}},
+		{"![abs](/static/a.png) ![remote](https://example.com/a.png) ![up](../a.png) ![data](data:image/png;base64,AA)", nil},
+		// markdown images come first, then html, then shortcodes
+		{`<img src="b.png"> ![a](a.png)`, []string{"a.png", "b.png"}},
+	}
+
+	for _, test := range tests {
+		if got := contentImages(test.content); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("contentImages(%q) = %q, want %q", test.content, got, test.want)
+		}
+	}
+}
+
+func TestLocalImage(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      string
+		ok        bool
+	}{
+		{"a.png", "a.png", true},
+		{"./img/../a.png", "a.png", true},
+		{"img/a.png?width=200", "img/a.png", true},
+		{"", "", false},
+		{"#anchor", "", false},
+		{"/a.png", "", false},
+		{"../a.png", "", false},
+		{"http://example.com/a.png", "", false},
+		{"data:image/png;base64,AA", "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := localImage(test.reference)
+		if got != test.want || ok != test.ok {
+			t.Errorf("localImage(%q) = %q, %v, want %q, %v", test.reference, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestReplaceGroup(t *testing.T) {
+	re := regexp.MustCompile(`\[(\w+)\]`)
+
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"none", "none"},
+		{"[a]", "[A]"},
+		{"x [a] y [bc] z", "x [A] y [BC] z"},
+	}
+
+	for _, test := range tests {
+		if got := replaceGroup(re, test.s, strings.ToUpper); got != test.want {
+			t.Errorf("replaceGroup(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestWithImageURLs(t *testing.T) {
+	tests := []struct {
+		rewrite bool
+		id      string
+		content string
+		want    string
+	}{
+		{true, "7", "![a](./a.png) ![b](b.png)", "![a](images/7/a.png) ![b](b.png)"},
+		{true, "7", `<img src="a.png"> {{< figure src="a.png" >}}`, `<img src="images/7/a.png"> {{< figure src="images/7/a.png" >}}`},
+		{true, "", "![a](a.png)", "![a](a.png)"},
+		{false, "7", "![a](a.png)", "![a](a.png)"},
+	}
+
+	for _, test := range tests {
+		task := NewTaskWithStore(&config.Settings{RewriteImages: test.rewrite}, NewMemoryStore(), &NonInteractivePrompter{})
+		article := &Article{ID: test.id, Content: test.content, Images: []string{"a.png"}}
+
+		got := task.withImageURLs(article)
+		if got.Content != test.want {
+			t.Errorf("withImageURLs(%q) = %q, want %q", test.content, got.Content, test.want)
+		}
+
+		if article.Content != test.content {
+			t.Errorf("withImageURLs(%q) changed the article", test.content)
+		}
+	}
+}
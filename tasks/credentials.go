@@ -0,0 +1,171 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evcraddock/article-importer/config"
+	"github.com/evcraddock/article-importer/service"
+)
+
+//Login checks the credentials against the service and saves them, with the auth key,
+//to the encrypted credential file for the current profile
+func Login(ctx context.Context, settings *config.Settings, prompter Prompter) error {
+	secret, err := credentialSecret(settings, prompter, true)
+	if err != nil {
+		return err
+	}
+
+	store := config.NewCredentialStore(settings.Credentials.File, secret)
+
+	// unlock the file before asking for anything else so a wrong passphrase fails early
+	saved, err := store.Get(settings.Profile)
+	if err != nil {
+		return err
+	}
+
+	if saved == nil {
+		saved = &config.Credentials{}
+	}
+
+	credentials := config.Credentials{
+		Username: settings.Auth.UserName,
+		Password: settings.Auth.Password,
+		AuthKey:  settings.Auth.AuthKey,
+	}
+
+	if credentials.Username, err = prompter.String("Username", firstNonEmpty(credentials.Username, saved.Username), true); err != nil {
+		return err
+	}
+
+	if credentials.Password == "" {
+		if credentials.Password, err = prompter.Hidden("Password", "", true); err != nil {
+			return err
+		}
+	}
+
+	if credentials.AuthKey, err = prompter.Hidden("Auth Key", firstNonEmpty(credentials.AuthKey, saved.AuthKey), true); err != nil {
+		return err
+	}
+
+	loginSettings := *settings
+	loginSettings.Auth.UserName = credentials.Username
+	loginSettings.Auth.Password = credentials.Password
+	loginSettings.Auth.AuthKey = credentials.AuthKey
+
+	svc := service.NewHTTPService(&loginSettings)
+	if err := svc.Authenticate(ctx); err != nil {
+		return fmt.Errorf("Login failed: %w", err)
+	}
+
+	return store.Put(settings.Profile, credentials)
+}
+
+//Logout removes the saved credentials and cached auth token for the current profile
+func Logout(settings *config.Settings, prompter Prompter) error {
+	svc := service.NewHTTPService(settings)
+	svc.TokenFile = settings.Auth.TokenCacheFile
+	if err := svc.ClearToken(); err != nil {
+		return err
+	}
+
+	if !config.CredentialFileExists(settings.Credentials.File) {
+		return nil
+	}
+
+	secret, err := credentialSecret(settings, prompter, false)
+	if err != nil {
+		return err
+	}
+
+	return config.NewCredentialStore(settings.Credentials.File, secret).Delete(settings.Profile)
+}
+
+//loadCredentials fills in any credentials not already configured from the credential file
+func loadCredentials(settings *config.Settings, prompter Prompter) error {
+	auth := &settings.Auth
+	if auth.AuthKey != "" && auth.UserName != "" && auth.Password != "" {
+		return nil
+	}
+
+	if !config.CredentialFileExists(settings.Credentials.File) {
+		return nil
+	}
+
+	secret, err := credentialSecret(settings, prompter, false)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := config.NewCredentialStore(settings.Credentials.File, secret).Get(settings.Profile)
+	if err != nil || credentials == nil {
+		return err
+	}
+
+	// credentials from flags, the environment or the profile win over saved ones
+	if auth.UserName == "" || auth.UserName == credentials.Username {
+		auth.UserName = credentials.Username
+		auth.Password = firstNonEmpty(auth.Password, credentials.Password)
+	}
+
+	auth.AuthKey = firstNonEmpty(auth.AuthKey, credentials.AuthKey)
+
+	return nil
+}
+
+//credentialSecret returns the key file or passphrase for the credential file, prompting when neither is configured
+func credentialSecret(settings *config.Settings, prompter Prompter, confirmNew bool) ([]byte, error) {
+	secret, err := settings.Credentials.Secret()
+	if err != nil || secret != nil {
+		return secret, err
+	}
+
+	passphrase, err := prompter.Hidden("Credentials Passphrase", "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmNew && !config.CredentialFileExists(settings.Credentials.File) {
+		confirm, err := prompter.Hidden("Confirm Passphrase", "", true)
+		if err != nil {
+			return nil, err
+		}
+
+		if confirm != passphrase {
+			return nil, fmt.Errorf("Passphrases do not match")
+		}
+	}
+
+	settings.Credentials.Passphrase = passphrase
+	return []byte(passphrase), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+//credentialStore is a store that needs credentials before it can talk to the service
+type credentialStore interface {
+	ensureCredentials() error
+}
+
+//CheckCredentials loads the credentials the service needs, prompting for any that are missing, so
+//a command fails before it reads or changes any article when there is no auth key
+func (task *Task) CheckCredentials() error {
+	store := task.store
+	if task.recorder != nil {
+		store = task.recorder.store
+	}
+
+	if credentials, ok := store.(credentialStore); ok {
+		return credentials.ensureCredentials()
+	}
+
+	return nil
+}
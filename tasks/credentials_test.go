@@ -0,0 +1,55 @@
+package tasks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evcraddock/article-importer/config"
+)
+
+func TestCheckCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		auth    config.Authorization
+		dryRun  bool
+		wantErr string
+	}{
+		{"no auth key", config.Authorization{UserName: "erik", Password: "secret"}, false, "No auth key configured for profile default"},
+		{"no auth key in a dry run", config.Authorization{UserName: "erik", Password: "secret"}, true, "No auth key configured for profile default"},
+		{"no password", config.Authorization{AuthKey: "key", UserName: "erik"}, false, "missing value Password"},
+		{"configured", config.Authorization{AuthKey: "key", UserName: "erik", Password: "secret"}, false, ""},
+	}
+
+	for _, test := range tests {
+		test.auth.ServiceURL = "http://localhost:9000"
+		settings := &config.Settings{
+			Profile:     "default",
+			NoInput:     true,
+			DryRun:      test.dryRun,
+			Auth:        test.auth,
+			Credentials: config.CredentialSettings{File: filepath.Join(dir, "credentials")},
+		}
+
+		task, err := NewTask(settings)
+		if err != nil {
+			t.Fatalf("%s: NewTask failed: %s", test.name, err)
+		}
+
+		err = task.CheckCredentials()
+		if test.wantErr == "" && err != nil {
+			t.Errorf("%s: CheckCredentials failed: %s", test.name, err)
+		} else if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+			t.Errorf("%s: CheckCredentials returned %v, want %s", test.name, err, test.wantErr)
+		}
+	}
+}
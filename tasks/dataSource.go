@@ -0,0 +1,137 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//dataSource returns the DataSource stored for an article file: its path relative to the content
+//root, with forward slashes. Without a content root, or for a file outside it, it is the path itself.
+func (task *Task) dataSource(path string) string {
+	root := task.settings.ContentRoot
+	if root == "" {
+		return path
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return path
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+//localPath returns where the file of a DataSource is on this machine
+func (task *Task) localPath(dataSource string) string {
+	path := filepath.FromSlash(dataSource)
+	if task.settings.ContentRoot == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(task.settings.ContentRoot, path)
+}
+
+//resolveImage returns the file of an image listed by an article file. Images starting with /
+//are relative to the content root, others to the article's folder.
+func resolveImage(contentRoot, articleFile, image string) string {
+	if contentRoot != "" && strings.HasPrefix(image, "/") {
+		return filepath.Join(contentRoot, filepath.FromSlash(strings.TrimPrefix(image, "/")))
+	}
+
+	return filepath.Join(filepath.Dir(articleFile), filepath.FromSlash(image))
+}
+
+//Normalize rewrites the dataSource of every article file under the content root as the file's
+//path relative to the root, returning the files that changed
+func (articleTask *Task) Normalize(ctx context.Context, filedir string) ([]string, error) {
+	if articleTask.settings.ContentRoot == "" {
+		return nil, fmt.Errorf("No content root configured, use --content-root, Article_Location or contentRoot in the profile")
+	}
+
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	var changed []string
+	subDirToSkip := []string{".git", ".DS_Store", stateDir}
+	err := filepath.Walk(filedir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if info.IsDir() && contains(subDirToSkip, info.Name()) {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || filepath.Ext(info.Name()) != ".md" {
+			return nil
+		}
+
+		normalized, err := articleTask.normalizeFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+
+		if normalized {
+			changed = append(changed, path)
+		}
+
+		return nil
+	})
+
+	return changed, err
+}
+
+//normalizeFile rewrites only the dataSource of an article file, returning whether it changed
+func (articleTask *Task) normalizeFile(path string) (bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	var current struct {
+		DataSource string `yaml:"dataSource"`
+	}
+
+	content, _, err := unmarshalFrontMatter(data, &current)
+	if err != nil {
+		return false, err
+	}
+
+	dataSource := articleTask.dataSource(path)
+	if current.DataSource == dataSource {
+		return false, nil
+	}
+
+	current.DataSource = dataSource
+	updated, err := marshalFrontMatter(data, &current, nil, content)
+	if err != nil {
+		return false, err
+	}
+
+	articleTask.printf("normalizing %s: dataSource %s \n", path, dataSource)
+	if articleTask.dryRun() {
+		articleTask.recordFile("write file", path)
+		return true, nil
+	}
+
+	return true, ioutil.WriteFile(path, updated, 0644)
+}
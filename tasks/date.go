@@ -0,0 +1,84 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//zonedLayouts are the publish date formats that carry their own offset
+var zonedLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04 -0700",
+	time.RFC1123Z,
+}
+
+//localLayouts are the publish date formats read in the site timezone, including the month first
+//format older versions wrote
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"01/02/2006 15:04",
+	"01/02/2006",
+}
+
+//parseDate reads a publish date, dates without an offset are in location
+func parseDate(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range zonedLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	for _, layout := range localLayouts {
+		if date, err := time.ParseInLocation(layout, value, location); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid date %q, use yyyy-mm-dd, yyyy-mm-dd hh:mm or RFC 3339", value)
+}
+
+//formatDate writes a publish date in location as an ISO date, or an RFC 3339 timestamp when
+//it has a time of day, so parseDate reads back the same time
+func formatDate(date time.Time, location *time.Location) string {
+	date = date.In(location)
+	if isMidnight(date) {
+		return date.Format("2006-01-02")
+	}
+
+	return date.Format(time.RFC3339)
+}
+
+//hashDate is the publish date as hashed for change detection. Dates without a time of day keep
+//the format older versions hashed, so their saved hashes still match.
+func hashDate(date time.Time, location *time.Location) string {
+	date = date.In(location)
+	if isMidnight(date) {
+		return date.Format("01/02/2006")
+	}
+
+	return date.UTC().Format(time.RFC3339)
+}
+
+func isMidnight(date time.Time) bool {
+	return date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0
+}
+
+//location returns the site timezone
+func (task *Task) location() *time.Location {
+	if task.settings.Timezone == nil {
+		return time.UTC
+	}
+
+	return task.settings.Timezone
+}
@@ -0,0 +1,162 @@
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// +3d, in 2 hours
+	relativeExpression = regexp.MustCompile(`^(?:\+|in\s+)(\d+)\s*([a-z]+)$`)
+	// tomorrow, next friday, 2026-11-02, each optionally followed by a time
+	dayExpression = regexp.MustCompile(`^(today|tomorrow|yesterday|(?:(this|next)\s+)?(sunday|monday|tuesday|wednesday|thursday|friday|saturday)|\d{4}-\d{2}-\d{2})(?:\s+(?:at\s+)?(.+))?$`)
+	// 9am, 9:30 pm, 14:00
+	timeExpression = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+//parseDateExpression reads a publish date as parseDate does, or as an expression relative to now
+//such as tomorrow 9am, next friday, friday at 5pm, +3d or in 2 hours. Days without a time are
+//at midnight and everything is in location. A weekday is the first one after today, or today
+//itself with this, as in this friday.
+func parseDateExpression(value string, now time.Time, location *time.Location) (time.Time, error) {
+	if date, err := parseDate(value, location); err == nil {
+		return date, nil
+	}
+
+	expression := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	now = now.In(location)
+
+	invalid := fmt.Errorf("Invalid date %q, use yyyy-mm-dd hh:mm, RFC 3339 or an expression like tomorrow 9am, next friday or +3d", value)
+
+	if expression == "now" {
+		return now.Truncate(time.Second), nil
+	}
+
+	if match := relativeExpression.FindStringSubmatch(expression); match != nil {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, invalid
+		}
+
+		now = now.Truncate(time.Minute)
+		switch match[2] {
+		case "m", "min", "mins", "minute", "minutes":
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case "h", "hr", "hrs", "hour", "hours":
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case "d", "day", "days":
+			return now.AddDate(0, 0, n), nil
+		case "w", "wk", "wks", "week", "weeks":
+			return now.AddDate(0, 0, 7*n), nil
+		}
+
+		return time.Time{}, invalid
+	}
+
+	day := now
+	clock := expression
+	if match := dayExpression.FindStringSubmatch(expression); match != nil {
+		switch {
+		case match[1] == "today":
+		case match[1] == "tomorrow":
+			day = now.AddDate(0, 0, 1)
+		case match[1] == "yesterday":
+			day = now.AddDate(0, 0, -1)
+		case match[3] != "":
+			days := (int(weekdays[match[3]]) - int(now.Weekday()) + 7) % 7
+			if days == 0 && match[2] != "this" {
+				days = 7
+			}
+
+			day = now.AddDate(0, 0, days)
+		default:
+			date, err := time.ParseInLocation("2006-01-02", match[1], location)
+			if err != nil {
+				return time.Time{}, invalid
+			}
+
+			day = date
+		}
+
+		clock = match[4]
+	}
+
+	hour, minute, ok := parseClock(clock)
+	if !ok {
+		return time.Time{}, invalid
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, location), nil
+}
+
+//parseClock reads a time of day like 9am, 9:30pm, 14:00, noon or midnight, empty is midnight
+func parseClock(value string) (hour, minute int, ok bool) {
+	switch value {
+	case "", "midnight":
+		return 0, 0, true
+	case "noon":
+		return 12, 0, true
+	}
+
+	match := timeExpression.FindStringSubmatch(value)
+	if match == nil {
+		return 0, 0, false
+	}
+
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	if minute > 59 {
+		return 0, 0, false
+	}
+
+	switch match[3] {
+	case "":
+		return hour, minute, hour <= 23
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+
+		return hour % 12, minute, true
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+
+		return hour%12 + 12, minute, true
+	}
+}
+
+//describeDate writes a date in location for the user to check
+func describeDate(date time.Time, location *time.Location) string {
+	return date.In(location).Format("Monday, 02 Jan 2006 15:04 MST")
+}
+
+//SetPublishAt sets the publish date of the articles the task loads from an expression
+//parseDateExpression understands, printing the date it resolves to
+func (task *Task) SetPublishAt(expression string) error {
+	date, err := parseDateExpression(expression, time.Now(), task.location())
+	if err != nil {
+		return err
+	}
+
+	task.publishAt = date
+	task.printf("publish date %s \n", describeDate(date, task.location()))
+	return nil
+}
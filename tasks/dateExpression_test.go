@@ -0,0 +1,61 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateExpression(t *testing.T) {
+	// a Sunday
+	now := time.Date(2026, 10, 18, 14, 23, 45, 0, central)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"now", time.Date(2026, 10, 18, 14, 23, 45, 0, central)},
+		{"+30m", time.Date(2026, 10, 18, 14, 53, 0, 0, central)},
+		{"+2h", time.Date(2026, 10, 18, 16, 23, 0, 0, central)},
+		{"in 2 hours", time.Date(2026, 10, 18, 16, 23, 0, 0, central)},
+		{"+3d", time.Date(2026, 10, 21, 14, 23, 0, 0, central)},
+		{"in 1 week", time.Date(2026, 10, 25, 14, 23, 0, 0, central)},
+		{"today", time.Date(2026, 10, 18, 0, 0, 0, 0, central)},
+		{"today noon", time.Date(2026, 10, 18, 12, 0, 0, 0, central)},
+		{"tomorrow", time.Date(2026, 10, 19, 0, 0, 0, 0, central)},
+		{"Tomorrow  9am", time.Date(2026, 10, 19, 9, 0, 0, 0, central)},
+		{"yesterday at 11:15pm", time.Date(2026, 10, 17, 23, 15, 0, 0, central)},
+		{"friday", time.Date(2026, 10, 23, 0, 0, 0, 0, central)},
+		{"friday at 5pm", time.Date(2026, 10, 23, 17, 0, 0, 0, central)},
+		{"next friday", time.Date(2026, 10, 23, 0, 0, 0, 0, central)},
+		{"sunday", time.Date(2026, 10, 25, 0, 0, 0, 0, central)},
+		{"next sunday", time.Date(2026, 10, 25, 0, 0, 0, 0, central)},
+		{"this sunday 18:00", time.Date(2026, 10, 18, 18, 0, 0, 0, central)},
+		{"2026-11-02 at 12am", time.Date(2026, 11, 2, 0, 0, 0, 0, central)},
+		{"2026-11-02 12pm", time.Date(2026, 11, 2, 12, 0, 0, 0, central)},
+		{"9:30am", time.Date(2026, 10, 18, 9, 30, 0, 0, central)},
+		{"2026-11-02 09:30", time.Date(2026, 11, 2, 9, 30, 0, 0, central)},
+		{"2026-11-02T09:30:00Z", time.Date(2026, 11, 2, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		got, err := parseDateExpression(test.value, now, central)
+		if err != nil {
+			t.Errorf("parseDateExpression(%q) failed: %s", test.value, err)
+			continue
+		}
+
+		if !got.Equal(test.want) {
+			t.Errorf("parseDateExpression(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseDateExpressionInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 18, 14, 23, 45, 0, central)
+
+	for _, value := range []string{"soon", "+3y", "tomorrow 25:00", "13pm", "friday at 9:75", "last friday"} {
+		if got, err := parseDateExpression(value, now, central); err == nil {
+			t.Errorf("parseDateExpression(%q) = %v, want an error", value, got)
+		}
+	}
+}
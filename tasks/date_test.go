@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+var central = time.FixedZone("CDT", -5*60*60)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-10-18", time.Date(2026, 10, 18, 0, 0, 0, 0, central)},
+		{" 2026-10-18 ", time.Date(2026, 10, 18, 0, 0, 0, 0, central)},
+		{"2026-10-18 09:30", time.Date(2026, 10, 18, 9, 30, 0, 0, central)},
+		{"2026-10-18T09:30:15", time.Date(2026, 10, 18, 9, 30, 15, 0, central)},
+		{"10/18/2026", time.Date(2026, 10, 18, 0, 0, 0, 0, central)},
+		{"2026-10-18T09:30:00Z", time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-18T09:30:00+02:00", time.Date(2026, 10, 18, 7, 30, 0, 0, time.UTC)},
+		{"2026-10-18 09:30:00 -0700", time.Date(2026, 10, 18, 16, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		got, err := parseDate(test.value, central)
+		if err != nil {
+			t.Errorf("parseDate(%q) failed: %s", test.value, err)
+			continue
+		}
+
+		if !got.Equal(test.want) {
+			t.Errorf("parseDate(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "tomorrow", "2026-13-01", "18/10/2026"} {
+		if _, err := parseDate(value, central); err == nil {
+			t.Errorf("parseDate(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		wantDate string
+		wantHash string
+	}{
+		{time.Date(2026, 10, 18, 0, 0, 0, 0, central), "2026-10-18", "10/18/2026"},
+		{time.Date(2026, 10, 18, 9, 30, 0, 0, central), "2026-10-18T09:30:00-05:00", "2026-10-18T14:30:00Z"},
+		// midnight in the site timezone, not in UTC
+		{time.Date(2026, 10, 18, 5, 0, 0, 0, time.UTC), "2026-10-18", "10/18/2026"},
+	}
+
+	for _, test := range tests {
+		if got := formatDate(test.date, central); got != test.wantDate {
+			t.Errorf("formatDate(%v) = %q, want %q", test.date, got, test.wantDate)
+		}
+
+		if got := hashDate(test.date, central); got != test.wantHash {
+			t.Errorf("hashDate(%v) = %q, want %q", test.date, got, test.wantHash)
+		}
+
+		parsed, err := parseDate(formatDate(test.date, central), central)
+		if err != nil || !parsed.Equal(test.date) {
+			t.Errorf("parseDate(formatDate(%v)) = %v, %v", test.date, parsed, err)
+		}
+	}
+}
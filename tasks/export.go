@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//PullArticle writes an article from the service, and its images, to a markdown file in filedir
+func (articleTask *Task) PullArticle(ctx context.Context, id string, filedir string) (*Article, error) {
+	var err error
+	if id == "" {
+		if id, err = articleTask.prompter.String("Article Id", id, true); err != nil {
+			return nil, err
+		}
+	}
+
+	filedir, err = articleTask.exportDir(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := articleTask.store.GetArticle(ctx, id)
+	if err == ErrNotFound {
+		return nil, fmt.Errorf("Article %s not found", id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := articleTask.openState(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := articleTask.exportArticle(ctx, state, filedir, article); err != nil {
+		return nil, err
+	}
+
+	return article, state.save()
+}
+
+//ExportArticles writes every article on the service, and their images, to markdown files in filedir
+func (articleTask *Task) ExportArticles(ctx context.Context, filedir string) ([]*Article, error) {
+	filedir, err := articleTask.exportDir(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := articleTask.store.ListArticles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := articleTask.openState(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	var exported []*Article
+	for _, article := range articles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		articleTask.printf("exporting article: %s (Id: %s) \n", article.Title, article.ID)
+		if err := articleTask.exportArticle(ctx, state, filedir, article); err != nil {
+			state.save()
+			return exported, fmt.Errorf("%s: %w", article.ID, err)
+		}
+
+		exported = append(exported, article)
+	}
+
+	if err := state.save(); err != nil {
+		return exported, err
+	}
+
+	return exported, ctx.Err()
+}
+
+func (articleTask *Task) exportDir(filedir string) (string, error) {
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	if filedir != "" {
+		return filedir, nil
+	}
+
+	return articleTask.prompter.String("Article Folder", filedir, true)
+}
+
+//exportArticle writes the article as ImportArticle front matter markdown and downloads its images next to it
+func (articleTask *Task) exportArticle(ctx context.Context, state *syncState, filedir string, article *Article) error {
+	path := articleFilePath(filedir, article)
+	article.DataSource = articleTask.dataSource(path)
+	articlePath := filepath.Dir(path)
+
+	if !articleTask.dryRun() {
+		if err := os.MkdirAll(articlePath, 0755); err != nil {
+			return fmt.Errorf("Error creating directory: %s", err.Error())
+		}
+	}
+
+	if err := articleTask.downloadImages(ctx, articlePath, article); err != nil {
+		return err
+	}
+
+	if err := articleTask.saveMarkdownFile(*article); err != nil {
+		return err
+	}
+
+	state.record(path, article)
+	return nil
+}
+
+//downloadImages saves the article's images and banner in the article folder
+func (articleTask *Task) downloadImages(ctx context.Context, articlePath string, article *Article) error {
+	images := append([]string{}, article.Images...)
+	if article.Banner != "" && !contains(images, article.Banner) {
+		images = append(images, article.Banner)
+	}
+
+	for _, imageFilePath := range images {
+		if err := articleTask.downloadImage(ctx, articlePath, article.ID, imageFilePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//downloadImage saves an image to its path relative to the article folder
+func (articleTask *Task) downloadImage(ctx context.Context, articlePath, articleID, imageFilePath string) error {
+	imagepath := filepath.Join(articlePath, filepath.FromSlash(imageFilePath))
+	if !strings.HasPrefix(imagepath, filepath.Clean(articlePath)+string(filepath.Separator)) {
+		return fmt.Errorf("Image %s is outside the article folder", imageFilePath)
+	}
+
+	if articleTask.dryRun() {
+		articleTask.recordFile("download image", imagepath)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(imagepath), 0755); err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(imagepath), ".download-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	err = articleTask.store.DownloadImage(ctx, articleID, GetFileName(imageFilePath, "/"), tmpFile)
+	closeErr := tmpFile.Close()
+
+	if err == ErrNotFound {
+		articleTask.printf("Image %s not found for article %s \n", imageFilePath, articleID)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("Could not download image %s: %w", imageFilePath, err)
+	}
+
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if err := os.Chmod(tmpFile.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), imagepath)
+}
@@ -0,0 +1,304 @@
+package tasks
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+//frontMatterDelimiter starts and ends the yaml front matter of a markdown file
+const frontMatterDelimiter = "---"
+
+//splitFrontMatter splits a markdown file into its yaml front matter and content. line is the
+//line of the file the front matter starts on, for reporting positions within it.
+func splitFrontMatter(data []byte) (header []byte, content []byte, line int, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	first, rest := cutLine(data)
+	if string(bytes.TrimRight(first, " \t\r")) != frontMatterDelimiter {
+		return nil, nil, 0, fmt.Errorf("File does not start with %s", frontMatterDelimiter)
+	}
+
+	offset := len(data) - len(rest)
+	for len(rest) > 0 {
+		end := len(data) - len(rest)
+
+		var current []byte
+		current, rest = cutLine(rest)
+		if string(bytes.TrimRight(current, " \t\r")) == frontMatterDelimiter {
+			return data[offset:end], rest, 2, nil
+		}
+	}
+
+	return nil, nil, 0, fmt.Errorf("Front matter is not closed with %s", frontMatterDelimiter)
+}
+
+//cutLine returns the first line of data, without its line feed, and the data after it
+func cutLine(data []byte) ([]byte, []byte) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return data, nil
+	}
+
+	return data[:i], data[i+1:]
+}
+
+//unmarshalFrontMatter decodes the front matter of a markdown file into v, returning the content
+//after it and every front matter field by key
+func unmarshalFrontMatter(data []byte, v interface{}) (string, map[string]interface{}, error) {
+	header, content, _, err := splitFrontMatter(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := yaml.Unmarshal(header, v); err != nil {
+		return "", nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := yaml.Unmarshal(header, &fields); err != nil {
+		return "", nil, err
+	}
+
+	return string(content), fields, nil
+}
+
+//marshalFrontMatter writes v and extra as front matter followed by content. When existing has
+//front matter its keys, their order and its comments are kept: only values that differ are
+//replaced, and fields of v with a value that it doesn't have are added. Fields of v left out,
+//like empty omitempty fields, are removed.
+func marshalFrontMatter(existing []byte, v interface{}, extra map[string]interface{}, content string) ([]byte, error) {
+	var fresh yaml.Node
+	if err := fresh.Encode(v); err != nil {
+		return nil, err
+	}
+
+	if err := appendExtra(&fresh, extra); err != nil {
+		return nil, err
+	}
+
+	document := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&fresh}}
+	if header, _, _, err := splitFrontMatter(existing); err == nil {
+		var current yaml.Node
+		err := yaml.Unmarshal(header, &current)
+		if err == nil && len(current.Content) == 1 && current.Content[0].Kind == yaml.MappingNode {
+			mergeMapping(current.Content[0], &fresh, yamlKeys(reflect.TypeOf(v)))
+			document = &current
+		}
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(frontMatterDelimiter + "\n")
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	buffer.WriteString(frontMatterDelimiter + "\n")
+	buffer.WriteString(content)
+
+	return buffer.Bytes(), nil
+}
+
+//appendExtra adds the extra fields, in key order, that aren't already in the mapping
+func appendExtra(mapping *yaml.Node, extra map[string]interface{}) error {
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		if mappingValue(mapping, key) == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var value yaml.Node
+		if err := value.Encode(extra[key]); err != nil {
+			return fmt.Errorf("Error marshaling %s: %s", key, err.Error())
+		}
+
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+
+	return nil
+}
+
+//mergeMapping updates current with the values of fresh, removing the known keys fresh doesn't have
+func mergeMapping(current, fresh *yaml.Node, known map[string]bool) {
+	seen := make(map[string]bool)
+
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(current.Content); i += 2 {
+		key, value := current.Content[i], current.Content[i+1]
+
+		freshValue := mappingValue(fresh, key.Value)
+		if freshValue == nil {
+			if !known[key.Value] {
+				merged = append(merged, key, value)
+			}
+
+			continue
+		}
+
+		seen[key.Value] = true
+		if !sameValue(value, freshValue) {
+			replaceValue(value, freshValue)
+		}
+
+		merged = append(merged, key, value)
+	}
+
+	// new fields are only added when they have a value, to leave the file as it was where possible
+	for i := 0; i+1 < len(fresh.Content); i += 2 {
+		if !seen[fresh.Content[i].Value] && !emptyNode(fresh.Content[i+1]) {
+			merged = append(merged, fresh.Content[i], fresh.Content[i+1])
+		}
+	}
+
+	current.Content = merged
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+//sameValue compares the text of two nodes, ignoring how they are quoted or tagged, so a plain
+//2020-01-02 in the file is the same as the string the article writes
+func sameValue(a, b *yaml.Node) bool {
+	a, b = resolveAlias(a), resolveAlias(b)
+
+	// an empty list and no value mean the same for the article
+	if emptyNode(a) && emptyNode(b) {
+		return true
+	}
+
+	if a.Kind != b.Kind {
+		return false
+	}
+
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+	case yaml.SequenceNode, yaml.MappingNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+
+		for i := range a.Content {
+			if !sameValue(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
+
+func emptyNode(node *yaml.Node) bool {
+	var value interface{}
+	return node.Decode(&value) == nil && isEmpty(value)
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case string:
+		return v == ""
+	}
+
+	return false
+}
+
+//replaceValue gives node the value of replacement, keeping the comments around node. A scalar
+//keeps its quoting, and its tag when the new value is read the same way, so a plain date is
+//replaced by a plain date.
+func replaceValue(node, replacement *yaml.Node) {
+	headComment, lineComment, footComment := node.HeadComment, node.LineComment, node.FootComment
+	style, tag, scalar := node.Style, node.Tag, node.Kind == yaml.ScalarNode
+
+	*node = *replacement
+	node.HeadComment, node.LineComment, node.FootComment = headComment, lineComment, footComment
+
+	if scalar && replacement.Kind == yaml.ScalarNode {
+		node.Style = style
+		if style == 0 && plainTag(replacement.Value) == tag {
+			node.Tag = tag
+		}
+	}
+}
+
+//plainTag returns the tag a value has when written without quotes, empty when it can't be
+func plainTag(value string) string {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &document); err != nil || len(document.Content) != 1 {
+		return ""
+	}
+
+	node := document.Content[0]
+	if node.Kind != yaml.ScalarNode || node.Style != 0 || node.Value != value {
+		return ""
+	}
+
+	return node.Tag
+}
+
+//hasField reports whether the front matter of data sets key
+func hasField(data []byte, key string) bool {
+	header, _, _, err := splitFrontMatter(data)
+	if err != nil {
+		return false
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(header, &document); err != nil || len(document.Content) != 1 {
+		return false
+	}
+
+	return document.Content[0].Kind == yaml.MappingNode && mappingValue(document.Content[0], key) != nil
+}
+
+//yamlKeys returns the front matter keys of a struct's fields
+func yamlKeys(t reflect.Type) map[string]bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const frontMatterFile = `---
+# the article
+id: "42"
+title: First Post
+url: first-post.md
+images:
+  - banner.png
+banner: banner.png
+publishDate: 2026-10-18 # midnight
+dataSource: local://first-post/first-post.md
+author: Erik
+categories: [go]
+tags:
+  - yaml
+layout: post
+---
+Some *content*.
+`
+
+func TestMarshalFrontMatter(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(article *ImportArticle)
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "unchanged",
+			change: func(article *ImportArticle) {},
+		},
+		{
+			name:   "date",
+			change: func(article *ImportArticle) { article.PublishDate = "2026-10-19" },
+			want:   []string{"publishDate: 2026-10-19 # midnight\n", "# the article\n", "layout: post\n", `id: "42"`},
+		},
+		{
+			name:   "title",
+			change: func(article *ImportArticle) { article.Title = "Second Post" },
+			want:   []string{"title: Second Post\n", "publishDate: 2026-10-18 # midnight\n"},
+		},
+		{
+			name:   "status",
+			change: func(article *ImportArticle) { article.Status = StatusDraft },
+			want:   []string{"layout: post\nstatus: draft\n"},
+		},
+		{
+			name:   "removed field",
+			change: func(article *ImportArticle) { article.ID = "" },
+			absent: []string{"id:"},
+		},
+	}
+
+	for _, test := range tests {
+		article := new(ImportArticle)
+		content, _, err := unmarshalFrontMatter([]byte(frontMatterFile), article)
+		if err != nil {
+			t.Fatalf("unmarshalFrontMatter failed: %s", err)
+		}
+
+		test.change(article)
+
+		data, err := marshalFrontMatter([]byte(frontMatterFile), article, nil, content)
+		if err != nil {
+			t.Errorf("%s: marshalFrontMatter failed: %s", test.name, err)
+			continue
+		}
+
+		got := string(data)
+		if test.want == nil && test.absent == nil && got != frontMatterFile {
+			t.Errorf("%s: marshalFrontMatter changed the file:\n%s", test.name, got)
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: marshalFrontMatter wrote\n%s\nwithout %q", test.name, got, want)
+			}
+		}
+
+		for _, absent := range test.absent {
+			if strings.Contains(got, absent) {
+				t.Errorf("%s: marshalFrontMatter wrote\n%s\nwith %q", test.name, got, absent)
+			}
+		}
+
+		if !strings.HasSuffix(got, "---\nSome *content*.\n") {
+			t.Errorf("%s: marshalFrontMatter lost the content:\n%s", test.name, got)
+		}
+	}
+}
+
+func TestMarshalFrontMatterNewFile(t *testing.T) {
+	article := &ImportArticle{Title: "New", URL: "new.md", PublishDate: "2026-10-18"}
+	extra := map[string]interface{}{"layout": "post", "aliases": []string{"/old"}}
+
+	data, err := marshalFrontMatter(nil, article, extra, "Body\n")
+	if err != nil {
+		t.Fatalf("marshalFrontMatter failed: %s", err)
+	}
+
+	decoded := new(ImportArticle)
+	content, fields, err := unmarshalFrontMatter(data, decoded)
+	if err != nil {
+		t.Fatalf("unmarshalFrontMatter failed: %s\n%s", err, data)
+	}
+
+	if content != "Body\n" || decoded.Title != "New" || decoded.PublishDate != "2026-10-18" {
+		t.Errorf("round trip = %+v, %q", decoded, content)
+	}
+
+	if fields["layout"] != "post" || fields["status"] != nil || fields["id"] != nil {
+		t.Errorf("round trip fields = %v", fields)
+	}
+}
+
+func TestSameValue(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2026-10-18", `"2026-10-18"`, true},
+		{"42", `"42"`, true},
+		{"[]", "", true},
+		{"[a, b]", "- a\n- b", true},
+		{"[a, b]", "[b, a]", false},
+		{"a", "b", false},
+		{"a", "[a]", false},
+	}
+
+	for _, test := range tests {
+		a, b := parseNode(t, test.a), parseNode(t, test.b)
+		if got := sameValue(a, b); got != test.want {
+			t.Errorf("sameValue(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func parseNode(t *testing.T, value string) *yaml.Node {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &document); err != nil {
+		t.Fatalf("yaml.Unmarshal(%q) failed: %s", value, err)
+	}
+
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	}
+
+	return document.Content[0]
+}
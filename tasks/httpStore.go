@@ -0,0 +1,240 @@
+package tasks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/evcraddock/article-importer/config"
+	"github.com/evcraddock/article-importer/service"
+)
+
+//httpStore is an ArticleStore backed by the article service
+type httpStore struct {
+	service  *service.HTTPService
+	settings *config.Settings
+	prompter Prompter
+
+	credentialLock sync.Mutex
+	credentialsSet bool
+}
+
+func newHTTPStore(svc *service.HTTPService, settings *config.Settings, prompter Prompter) *httpStore {
+	return &httpStore{
+		service:  svc,
+		settings: settings,
+		prompter: prompter,
+	}
+}
+
+//listPageSize is the number of articles requested per page when listing articles
+const listPageSize = 50
+
+//ListArticles gets every article from the service a page at a time
+func (store *httpStore) ListArticles(ctx context.Context) ([]*Article, error) {
+	var articles []*Article
+	seen := make(map[string]bool)
+
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("limit", strconv.Itoa(listPageSize))
+
+		var pageArticles []*Article
+		if err := store.service.GetList(ctx, "articles", query, &pageArticles); err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, article := range pageArticles {
+			if !seen[article.ID] {
+				seen[article.ID] = true
+				articles = append(articles, article)
+				added++
+			}
+		}
+
+		// a service that ignores paging sends the same articles again
+		if len(pageArticles) < listPageSize || added == 0 {
+			return articles, nil
+		}
+	}
+}
+
+//GetArticle gets an article from the service
+func (store *httpStore) GetArticle(ctx context.Context, id string) (*Article, error) {
+	article := &Article{}
+	err := store.service.Get(ctx, "articles", id, article)
+	if service.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if article.ID == "" {
+		return nil, ErrNotFound
+	}
+
+	return article, nil
+}
+
+//CreateArticle posts a new article to the service
+func (store *httpStore) CreateArticle(ctx context.Context, article *Article) error {
+	if err := store.ensureCredentials(); err != nil {
+		return err
+	}
+
+	return store.service.SendRequest(ctx, "POST", "articles", article)
+}
+
+//UpdateArticle puts an existing article to the service
+func (store *httpStore) UpdateArticle(ctx context.Context, article *Article) error {
+	if err := store.ensureCredentials(); err != nil {
+		return err
+	}
+
+	return store.service.SendRequest(ctx, "PUT", "articles/"+article.ID, article)
+}
+
+//DeleteArticle deletes an article from the service
+func (store *httpStore) DeleteArticle(ctx context.Context, id string) error {
+	if err := store.ensureCredentials(); err != nil {
+		return err
+	}
+
+	return store.service.SendRequest(ctx, "DELETE", "articles/"+id, nil)
+}
+
+//SaveLink posts or puts a link to the service
+func (store *httpStore) SaveLink(ctx context.Context, link *Link) error {
+	if err := store.ensureCredentials(); err != nil {
+		return err
+	}
+
+	if link.ID == "" {
+		return store.service.SendRequest(ctx, "POST", "links", link)
+	}
+
+	return store.service.SendRequest(ctx, "PUT", "links/"+link.ID, link)
+}
+
+//DeleteLink deletes a link from the service
+func (store *httpStore) DeleteLink(ctx context.Context, id string) error {
+	if err := store.ensureCredentials(); err != nil {
+		return err
+	}
+
+	return store.service.SendRequest(ctx, "DELETE", "links/"+id, nil)
+}
+
+//UploadImage uploads an image for an article
+func (store *httpStore) UploadImage(ctx context.Context, articleID, path string) error {
+	if err := store.ensureCredentials(); err != nil {
+		return err
+	}
+
+	_, err := store.service.Upload(ctx, "images/"+articleID, path)
+	return err
+}
+
+//DownloadImage downloads an image for an article
+func (store *httpStore) DownloadImage(ctx context.Context, articleID, filename string, w io.Writer) error {
+	err := store.service.Download(ctx, "images/"+articleID+"/"+url.PathEscape(filename), w)
+	if service.IsNotFound(err) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+//ImageHash asks the service for the image's headers, the hash is sent in its own header or as the ETag
+func (store *httpStore) ImageHash(ctx context.Context, articleID, filename string) (string, bool, error) {
+	header, err := store.service.Head(ctx, "images/"+articleID+"/"+url.PathEscape(filename))
+	if service.IsNotFound(err) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	hash := strings.ToLower(header.Get(service.ContentHashHeader))
+	if etag := strings.ToLower(strings.Trim(header.Get("ETag"), `"`)); hash == "" && isSHA256(etag) {
+		hash = etag
+	}
+
+	return hash, true, nil
+}
+
+func isSHA256(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+//ImageURL returns the image link on the service
+func (store *httpStore) ImageURL(articleID, filename string) string {
+	return store.service.ServiceURL + "/images/" + articleID + "/" + url.PathEscape(filename)
+}
+
+//ensureCredentials loads saved credentials and prompts for anything else needed to talk to the
+//service that isn't configured. Username and password are only needed when there is no cached auth token.
+func (store *httpStore) ensureCredentials() error {
+	store.credentialLock.Lock()
+	defer store.credentialLock.Unlock()
+
+	if store.credentialsSet {
+		return nil
+	}
+
+	if err := loadCredentials(store.settings, store.prompter); err != nil {
+		return err
+	}
+
+	auth := store.settings.Auth
+	if auth.AuthKey == "" {
+		return fmt.Errorf("No auth key configured for profile %s, run login or set Article_Server_AuthKey", store.settings.Profile)
+	}
+
+	store.service.AuthKey = auth.AuthKey
+	store.service.Username = firstNonEmpty(store.service.Username, auth.UserName)
+	store.service.Password = firstNonEmpty(store.service.Password, auth.Password)
+
+	var err error
+	if store.service.ServiceURL == "" {
+		store.service.ServiceURL, err = store.prompter.String("Service Url", "", true)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !store.service.HasToken() {
+		if store.service.Username == "" {
+			store.service.Username, err = store.prompter.String("Username", "", true)
+			if err != nil {
+				return err
+			}
+		}
+
+		if store.service.Password == "" {
+			store.service.Password, err = store.prompter.Hidden("Password", "", true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	store.credentialsSet = true
+	return nil
+}
@@ -0,0 +1,91 @@
+package tasks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//imageCache records the hash of each image uploaded, so unchanged images need no request
+type imageCache struct {
+	fileName string
+	lock     sync.Mutex
+	changed  bool
+
+	Images map[string]string `json:"images"`
+}
+
+//loadImageCache reads the image cache, a missing or unreadable cache is just empty
+func loadImageCache(fileName string) *imageCache {
+	cache := &imageCache{
+		fileName: fileName,
+		Images:   make(map[string]string),
+	}
+
+	if fileName == "" {
+		return cache
+	}
+
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return cache
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil || cache.Images == nil {
+		cache.Images = make(map[string]string)
+	}
+
+	return cache
+}
+
+func imageCacheKey(articleID, filename string) string {
+	return articleID + "/" + filename
+}
+
+//get returns the hash of the image as it was last uploaded
+func (cache *imageCache) get(articleID, filename string) string {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	return cache.Images[imageCacheKey(articleID, filename)]
+}
+
+//put records the hash of an uploaded image
+func (cache *imageCache) put(articleID, filename, hash string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	key := imageCacheKey(articleID, filename)
+	if cache.Images[key] != hash {
+		cache.Images[key] = hash
+		cache.changed = true
+	}
+}
+
+//save writes the cache when it has changed
+func (cache *imageCache) save() error {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if !cache.changed || cache.fileName == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cache.fileName), 0755); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(cache.fileName, data, 0644); err != nil {
+		return err
+	}
+
+	cache.changed = false
+	return nil
+}
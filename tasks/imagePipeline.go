@@ -0,0 +1,289 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evcraddock/article-importer/config"
+	"golang.org/x/image/draw"
+)
+
+//processableImage reports whether the pipeline re-encodes an image, other formats are uploaded as they are
+func processableImage(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+
+	return false
+}
+
+//variantName returns the filename of a variant of an image
+func variantName(filename, variant string) string {
+	extension := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, extension) + "-" + variant + extension
+}
+
+//imageVariants returns the filenames of the variants uploaded with each of the article's images
+func (task *Task) imageVariants(article *Article) map[string]map[string]string {
+	settings := task.settings.Images
+	if !settings.Process || len(settings.Variants) == 0 {
+		return nil
+	}
+
+	variants := make(map[string]map[string]string)
+	for _, imageFilePath := range article.Images {
+		filename := GetFileName(imageFilePath, "/")
+		if !processableImage(filename) {
+			continue
+		}
+
+		names := make(map[string]string, len(settings.Variants))
+		for _, variant := range settings.Variants {
+			names[variant.Name] = variantName(filename, variant.Name)
+		}
+
+		variants[filename] = names
+	}
+
+	return variants
+}
+
+//prepareImage returns the files to upload for an image, the image itself when it isn't processed.
+//cleanup removes any files the pipeline wrote.
+func (task *Task) prepareImage(path string) (paths []string, cleanup func(), err error) {
+	if !task.settings.Images.Process || !processableImage(path) {
+		return []string{path}, func() {}, nil
+	}
+
+	dir, err := ioutil.TempDir("", "article-images-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup = func() { os.RemoveAll(dir) }
+
+	paths, err = processImage(path, dir, task.settings.Images)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return paths, cleanup, nil
+}
+
+//processImage writes the image, capped at the maximum dimension and re-encoded without its
+//metadata, and each of its variants into dir, returning their paths
+func processImage(path, dir string, settings config.ImageSettings) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode image %s: %s", path, err.Error())
+	}
+
+	// the orientation is lost with the rest of the metadata, so apply it to the pixels
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	filename := filepath.Base(path)
+	original := fit(img, settings.MaxDimension, settings.MaxDimension)
+
+	paths := []string{filepath.Join(dir, filename)}
+	if err := encodeImage(paths[0], original, format, settings.Quality); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range settings.Variants {
+		variantPath := filepath.Join(dir, variantName(filename, variant.Name))
+		if err := encodeImage(variantPath, fit(original, variant.Width, variant.Height), format, settings.Quality); err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, variantPath)
+	}
+
+	return paths, nil
+}
+
+//fit scales an image down to fit within width and height, a limit of 0 doesn't constrain that side
+func fit(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	scale := 1.0
+	if width > 0 && bounds.Dx() > width {
+		scale = float64(width) / float64(bounds.Dx())
+	}
+
+	if height > 0 && float64(bounds.Dy())*scale > float64(height) {
+		scale = float64(height) / float64(bounds.Dy())
+	}
+
+	if scale == 1.0 {
+		return img
+	}
+
+	targetWidth, targetHeight := int(float64(bounds.Dx())*scale+0.5), int(float64(bounds.Dy())*scale+0.5)
+	if targetWidth < 1 {
+		targetWidth = 1
+	}
+
+	if targetHeight < 1 {
+		targetHeight = 1
+	}
+
+	target := image.Rect(0, 0, targetWidth, targetHeight)
+	resized := image.NewRGBA(target)
+	draw.CatmullRom.Scale(resized, target, img, bounds, draw.Src, nil)
+
+	return resized
+}
+
+//encodeImage writes an image in its original format. Quality applies to JPEG, PNG is lossless
+//and always written at the best compression.
+func encodeImage(path string, img image.Image, format string, quality int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if format == "png" {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(file, img)
+	} else {
+		err = jpeg.Encode(file, img, &jpeg.Options{Quality: quality})
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("Could not encode image %s: %s", path, err.Error())
+	}
+
+	return nil
+}
+
+//jpegOrientation reads the EXIF orientation of a JPEG, 1 when there is none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + length
+	}
+
+	return 1
+}
+
+//exifOrientation finds the orientation tag in the first IFD of EXIF data
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+//orient turns an image the right way up for its EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// orientations 5 to 8 swap width and height
+	target := image.Rect(0, 0, width, height)
+	if orientation >= 5 {
+		target = image.Rect(0, 0, height, width)
+	}
+
+	oriented := image.NewRGBA(target)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+
+			oriented.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return oriented
+}
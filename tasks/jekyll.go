@@ -0,0 +1,373 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+//JekyllArticle represents the front matter of a Jekyll post
+type JekyllArticle struct {
+	Title      string     `yaml:"title"`
+	Date       string     `yaml:"date"`
+	Layout     string     `yaml:"layout"`
+	Permalink  string     `yaml:"permalink"`
+	Slug       string     `yaml:"slug"`
+	Published  *bool      `yaml:"published"`
+	Author     string     `yaml:"author"`
+	Banner     string     `yaml:"banner"`
+	Image      yaml.Node  `yaml:"image"`
+	Category   jekyllList `yaml:"category"`
+	Categories jekyllList `yaml:"categories"`
+	Tag        jekyllList `yaml:"tag"`
+	Tags       jekyllList `yaml:"tags"`
+}
+
+//jekyllList is a front matter list, which Jekyll also accepts as a space separated string
+type jekyllList []string
+
+func (list *jekyllList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			*list = nil
+		} else {
+			*list = strings.Fields(node.Value)
+		}
+
+		return nil
+	}
+
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+
+	*list = items
+	return nil
+}
+
+//jekyllPostName matches the YYYY-MM-DD-slug file names of posts
+var jekyllPostName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)\.(md|markdown)$`)
+
+var (
+	// {{ "/assets/img/a.png" | relative_url }}
+	jekyllFilteredAsset = regexp.MustCompile(`\{\{\s*["'](/?assets/[^"']+)["']\s*\|\s*(?:relative_url|absolute_url)\s*\}\}`)
+	// /assets/img/a.png, optionally after {{ site.url }}{{ site.baseurl }}
+	jekyllAsset = regexp.MustCompile(`(^|[\s("'=>])(?:\{\{\s*site\.(?:url|baseurl)\s*\}\})*(/assets/[^\s"'()<>{}]+)`)
+)
+
+//ImportJekyll imports the posts in the _posts folder of a Jekyll site into the article tree under
+//filedir, a folder per article as ImportArticle creates, with the assets they reference copied in
+func (articleTask *Task) ImportJekyll(ctx context.Context, site string, filedir string, options BatchOptions) (*BatchSummary, error) {
+	var err error
+	if site == "" {
+		if site, err = articleTask.prompter.String("Jekyll Site Folder", site, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	if filedir == "" {
+		if filedir, err = articleTask.prompter.String("Article Folder", filedir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	var paths []string
+	err = filepath.Walk(filepath.Join(site, "_posts"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && jekyllPostName.MatchString(info.Name()) {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	summary := articleTask.runBatch(ctx, paths, options, func(ctx context.Context, jobTask *Task, postPath string) BatchResult {
+		jobTask.printf("importing post: %s \n ", postPath)
+
+		article, err := jobTask.importJekyllPost(site, filedir, postPath)
+		if err != nil {
+			jobTask.printf("error: %s \n ", err.Error())
+			return BatchResult{Status: BatchFailed, Err: err}
+		}
+
+		return BatchResult{ID: article.ID, Status: BatchImported}
+	})
+
+	return summary, summary.err(ctx)
+}
+
+//importJekyllPost writes a Jekyll post as an article file in its own folder under filedir
+func (articleTask *Task) importJekyllPost(site, filedir, postPath string) (*Article, error) {
+	name := jekyllPostName.FindStringSubmatch(filepath.Base(postPath))
+	if name == nil {
+		return nil, fmt.Errorf("%s is not named YYYY-MM-DD-slug.md", postPath)
+	}
+
+	data, err := ioutil.ReadFile(postPath)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("Could not open file")
+	}
+
+	post := new(JekyllArticle)
+	content, fields, err := unmarshalFrontMatter(data, post)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling yaml file: %s", err.Error())
+	}
+
+	// the date in the front matter is more precise than the one in the file name
+	date := name[1]
+	if post.Date != "" {
+		date = post.Date
+	}
+
+	var article = &Article{
+		Title:   post.Title,
+		Author:  post.Author,
+		Status:  StatusPublished,
+		Content: content,
+		Extra:   jekyllExtra(post, fields),
+	}
+
+	article.PublishDate, err = parseDate(date, articleTask.location())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid date in %s: %s", postPath, err.Error())
+	}
+
+	if post.Published != nil && !*post.Published {
+		article.Status = StatusDraft
+	}
+
+	if article.Author == "" {
+		article.Author = articleTask.settings.Defaults.Author
+	}
+
+	slug := jekyllSlug(post, name[2])
+	articlePath := filepath.Join(filedir, slug)
+	if _, err := os.Stat(articlePath); os.IsNotExist(err) && articleTask.dryRun() {
+		articleTask.recordFile("create directory", articlePath)
+	} else if os.IsNotExist(err) {
+		if err := os.MkdirAll(articlePath, 0755); err != nil {
+			return nil, fmt.Errorf("Error creating directory: %s", err.Error())
+		}
+	}
+
+	assets := &jekyllAssets{task: articleTask, site: site, articlePath: articlePath, copied: make(map[string]string)}
+
+	banner := post.Banner
+	if banner == "" && post.Image.Kind == yaml.ScalarNode {
+		banner = post.Image.Value
+	}
+
+	if banner != "" {
+		article.Banner = GetFileName(banner, "/")
+		if copied, ok, err := assets.copy(banner); err != nil {
+			return nil, err
+		} else if ok {
+			article.Banner = copied
+		}
+
+		article.Images = []string{article.Banner}
+	}
+
+	article.Content, err = assets.rewrite(article.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range contentImages(article.Content) {
+		if !containsImage(article.Images, image) {
+			article.Images = append(article.Images, image)
+		}
+	}
+
+	for _, category := range append(post.Category, post.Categories...) {
+		article.Categories = append(article.Categories, strings.ToLower(category))
+	}
+
+	for _, tag := range append(post.Tag, post.Tags...) {
+		article.Tags = append(article.Tags, strings.ToLower(tag))
+	}
+
+	article.URL = slug + ".md"
+	article.DataSource = articleTask.dataSource(filepath.Join(articlePath, article.URL))
+
+	if err := articleTask.saveMarkdownFile(*article); err != nil {
+		return nil, err
+	}
+
+	return article, nil
+}
+
+//jekyllSlug returns the slug of a post from its slug or permalink, falling back to its file name
+func jekyllSlug(post *JekyllArticle, fileSlug string) string {
+	if post.Slug != "" {
+		return post.Slug
+	}
+
+	// permalinks with placeholders like /:categories/:title/ don't name the post
+	permalink := strings.Trim(post.Permalink, "/")
+	if permalink != "" && !strings.Contains(permalink, ":") {
+		return strings.TrimSuffix(GetFileName(permalink, "/"), ".html")
+	}
+
+	return fileSlug
+}
+
+//jekyllExtra returns the front matter fields kept in the article file besides the ones it maps,
+//including its layout and permalink
+func jekyllExtra(post *JekyllArticle, fields map[string]interface{}) map[string]interface{} {
+	known := yamlKeys(reflect.TypeOf(JekyllArticle{}))
+
+	extra := make(map[string]interface{})
+	for key, value := range fields {
+		if !known[key] {
+			extra[key] = value
+		}
+	}
+
+	if post.Layout != "" {
+		extra["layout"] = post.Layout
+	}
+
+	if post.Permalink != "" {
+		extra["permalink"] = post.Permalink
+	}
+
+	// an image given as a mapping of path, alt and so on isn't a banner
+	if post.Image.Kind != 0 && post.Image.Kind != yaml.ScalarNode {
+		extra["image"] = fields["image"]
+	}
+
+	if len(extra) == 0 {
+		return nil
+	}
+
+	return extra
+}
+
+//jekyllAssets copies the site assets a post references into its article folder
+type jekyllAssets struct {
+	task        *Task
+	site        string
+	articlePath string
+	copied      map[string]string
+}
+
+//rewrite copies the assets referenced in content and points the references at the copies
+func (assets *jekyllAssets) rewrite(content string) (string, error) {
+	var err error
+	replace := func(reference, prefix string) string {
+		copied, ok, copyErr := assets.copy(reference)
+		if copyErr != nil && err == nil {
+			err = copyErr
+		}
+
+		if !ok {
+			return ""
+		}
+
+		return prefix + copied
+	}
+
+	content = jekyllFilteredAsset.ReplaceAllStringFunc(content, func(match string) string {
+		if replaced := replace(jekyllFilteredAsset.FindStringSubmatch(match)[1], ""); replaced != "" {
+			return replaced
+		}
+
+		return match
+	})
+
+	content = jekyllAsset.ReplaceAllStringFunc(content, func(match string) string {
+		groups := jekyllAsset.FindStringSubmatch(match)
+		if replaced := replace(groups[2], groups[1]); replaced != "" {
+			return replaced
+		}
+
+		return match
+	})
+
+	return content, err
+}
+
+//copy copies a site asset into the article folder, returning its path relative to the article.
+//It returns false for a reference that isn't to a file in the site's assets.
+func (assets *jekyllAssets) copy(reference string) (string, bool, error) {
+	if i := strings.IndexAny(reference, "?#"); i >= 0 {
+		reference = reference[:i]
+	}
+
+	asset := path.Clean("/" + reference)
+	if !strings.HasPrefix(asset, "/assets/") {
+		return "", false, nil
+	}
+
+	source := filepath.Join(assets.site, filepath.FromSlash(asset))
+	if !fileExists(source) {
+		assets.task.printf("asset %s not found \n", source)
+		return "", false, nil
+	}
+
+	filename := path.Base(asset)
+	if existing, ok := assets.copied[filename]; ok {
+		if existing != source {
+			return "", false, fmt.Errorf("Assets %s and %s would both be copied as %s", existing, source, filename)
+		}
+
+		return filename, true, nil
+	}
+
+	assets.copied[filename] = source
+	target := filepath.Join(assets.articlePath, filename)
+	if assets.task.dryRun() {
+		assets.task.recordFile("copy file", target)
+		return filename, true, nil
+	}
+
+	if err := copyFile(source, target); err != nil {
+		return "", false, fmt.Errorf("Could not copy asset %s: %s", source, err.Error())
+	}
+
+	return filename, true, nil
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,189 @@
+package tasks
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/evcraddock/article-importer/config"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestJekyllList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  jekyllList
+	}{
+		{"tags: go yaml", jekyllList{"go", "yaml"}},
+		{"tags: [go, static sites]", jekyllList{"go", "static sites"}},
+		{"tags:\n  - go\n  - yaml", jekyllList{"go", "yaml"}},
+		{"tags:", nil},
+		{"title: no tags", nil},
+	}
+
+	for _, test := range tests {
+		var post JekyllArticle
+		if err := yaml.Unmarshal([]byte(test.value), &post); err != nil {
+			t.Errorf("yaml.Unmarshal(%q) failed: %s", test.value, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(post.Tags, test.want) {
+			t.Errorf("yaml.Unmarshal(%q) tags = %q, want %q", test.value, post.Tags, test.want)
+		}
+	}
+}
+
+func TestJekyllSlug(t *testing.T) {
+	tests := []struct {
+		post JekyllArticle
+		want string
+	}{
+		{JekyllArticle{}, "file-slug"},
+		{JekyllArticle{Slug: "the-slug", Permalink: "/blog/other/"}, "the-slug"},
+		{JekyllArticle{Permalink: "/blog/my-post/"}, "my-post"},
+		{JekyllArticle{Permalink: "/blog/my-post.html"}, "my-post"},
+		{JekyllArticle{Permalink: "/:categories/:title/"}, "file-slug"},
+	}
+
+	for _, test := range tests {
+		if got := jekyllSlug(&test.post, "file-slug"); got != test.want {
+			t.Errorf("jekyllSlug(%+v) = %q, want %q", test.post, got, test.want)
+		}
+	}
+}
+
+func TestJekyllAssets(t *testing.T) {
+	site, err := ioutil.TempDir("", "jekyll-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(site)
+
+	writeFile(t, filepath.Join(site, "assets", "img", "a.png"), "a")
+	writeFile(t, filepath.Join(site, "assets", "b.jpg"), "b")
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"![a](/assets/img/a.png)", "![a](a.png)"},
+		{`![a]({{ "/assets/img/a.png" | relative_url }})`, "![a](a.png)"},
+		{"![b]({{ site.baseurl }}/assets/b.jpg?v=1)", "![b](b.jpg)"},
+		{`<img src="/assets/b.jpg">`, `<img src="b.jpg">`},
+		{"![missing](/assets/missing.png)", "![missing](/assets/missing.png)"},
+		{"see /images/a.png and https://example.com/assets/a.png", "see /images/a.png and https://example.com/assets/a.png"},
+	}
+
+	for _, test := range tests {
+		articlePath, err := ioutil.TempDir("", "article-")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer os.RemoveAll(articlePath)
+
+		task := NewTaskWithStore(&config.Settings{}, NewMemoryStore(), &NonInteractivePrompter{})
+		task.out = ioutil.Discard
+		assets := &jekyllAssets{task: task, site: site, articlePath: articlePath, copied: make(map[string]string)}
+
+		got, err := assets.rewrite(test.content)
+		if err != nil {
+			t.Errorf("rewrite(%q) failed: %s", test.content, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("rewrite(%q) = %q, want %q", test.content, got, test.want)
+		}
+
+		for _, image := range contentImages(got) {
+			if !fileExists(filepath.Join(articlePath, image)) {
+				t.Errorf("rewrite(%q) didn't copy %s", test.content, image)
+			}
+		}
+	}
+}
+
+func TestImportJekyll(t *testing.T) {
+	site, err := ioutil.TempDir("", "jekyll-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(site)
+
+	filedir, err := ioutil.TempDir("", "articles-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(filedir)
+
+	writeFile(t, filepath.Join(site, "assets", "banner.png"), "banner")
+	writeFile(t, filepath.Join(site, "_posts", "2026-10-18-first-post.md"),
+		"---\nlayout: post\ntitle: First Post\ntags: go yaml\ncategories: [Blog]\nimage: /assets/banner.png\ncomments: true\n---\nHello\n")
+	writeFile(t, filepath.Join(site, "_posts", "drafts", "2026-10-19-second.markdown"),
+		"---\ntitle: Second\ndate: 2026-10-19 09:30:00 -0500\npublished: false\npermalink: /blog/second-post/\n---\nDraft\n")
+	writeFile(t, filepath.Join(site, "_posts", "notes.md"), "not a post")
+
+	task := NewTaskWithStore(&config.Settings{Defaults: config.Defaults{Author: "Erik"}}, NewMemoryStore(), &NonInteractivePrompter{})
+	task.out = ioutil.Discard
+
+	summary, err := task.ImportJekyll(context.Background(), site, filedir, BatchOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ImportJekyll failed: %s", err)
+	}
+
+	if len(summary.Results) != 2 || summary.Count(BatchImported) != 2 {
+		t.Fatalf("ImportJekyll results = %+v", summary.Results)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{
+			path: "first-post/first-post.md",
+			want: []string{"title: First Post\n", "publishDate: \"2026-10-18\"\n", "banner: banner.png\n", "author: Erik\n",
+				"categories:\n  - blog\n", "tags:\n  - go\n  - yaml\n", "comments: true\n", "layout: post\n", "---\nHello\n"},
+		},
+		{
+			path: "second-post/second-post.md",
+			want: []string{"title: Second\n", "status: draft\n", "publishDate: \"2026-10-19T14:30:00Z\"\n", "permalink: /blog/second-post/\n"},
+		},
+	}
+
+	for _, test := range tests {
+		data, err := ioutil.ReadFile(filepath.Join(filedir, filepath.FromSlash(test.path)))
+		if err != nil {
+			t.Errorf("ImportJekyll didn't write %s: %s", test.path, err)
+			continue
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("ImportJekyll wrote %s\n%s\nwithout %q", test.path, data, want)
+			}
+		}
+	}
+
+	if !fileExists(filepath.Join(filedir, "first-post", "banner.png")) {
+		t.Errorf("ImportJekyll didn't copy the banner")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
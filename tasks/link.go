@@ -1,7 +1,7 @@
 package tasks
 
 import (
-	"log"
+	"context"
 )
 
 //Link stores link information
@@ -15,40 +15,41 @@ type Link struct {
 	Tags       []string `json:"tags"`
 }
 
-func (linkTask *Task) saveLink(link *Link) (*Link, error) {
-	if linkTask.service.Username == "" {
-		linkTask.service.Username = AskForStringValue("Username", "", true)
+func (linkTask *Task) saveLink(ctx context.Context, link *Link) (*Link, error) {
+	var err error
+	prompter := linkTask.prompter
+
+	if link.Title, err = prompter.String("Title", link.Title, true); err != nil {
+		return link, err
 	}
 
-	if linkTask.service.Password == "" {
-		linkTask.service.Password = AskForHiddenStringValue("Password", "", true)
+	if link.LinkTitle, err = prompter.String("Link Title", link.LinkTitle, true); err != nil {
+		return link, err
 	}
 
-	if linkTask.service.ServiceURL == "" {
-		linkTask.service.ServiceURL = AskForStringValue("Service Url", "", true)
+	if link.URL, err = prompter.String("Permalink", link.URL, true); err != nil {
+		return link, err
 	}
 
-	if linkTask.service.AuthKey == "" {
-		log.Fatal("AuthKey environment variable must be set.")
+	if link.Banner, err = prompter.String("Banner Url", link.Banner, false); err != nil {
+		return link, err
 	}
 
-	link.Title = AskForStringValue("Title", link.Title, true)
-	link.LinkTitle = AskForStringValue("Link Title", link.LinkTitle, true)
-	link.URL = AskForStringValue("Permalink", link.URL, true)
-	link.Banner = AskForStringValue("Banner Url", link.Banner, false)
-	link.Categories = AskForCSV("Categories (csv)", link.Categories)
-	link.Tags = AskForCSV("Tags (csv)", link.Tags)
+	if link.Categories, err = prompter.CSV("Categories (csv)", link.Categories); err != nil {
+		return link, err
+	}
 
-	requestMethod := "POST"
-	requestURL := "links"
+	if link.Tags, err = prompter.CSV("Tags (csv)", link.Tags); err != nil {
+		return link, err
+	}
 
-	err := linkTask.service.SendRequest(requestMethod, requestURL, link)
+	err = linkTask.store.SaveLink(ctx, link)
 
 	return link, err
 }
 
 //CreateNewLink creates new Link
-func (linkTask *Task) CreateNewLink() (*Link, error) {
+func (linkTask *Task) CreateNewLink(ctx context.Context) (*Link, error) {
 	var link = &Link{
 		Title:     "",
 		LinkTitle: "",
@@ -56,29 +57,17 @@ func (linkTask *Task) CreateNewLink() (*Link, error) {
 		Banner:    "",
 	}
 
-	return linkTask.saveLink(link)
+	return linkTask.saveLink(ctx, link)
 }
 
 //DeleteLink deletes a specific link
-func (linkTask *Task) DeleteLink() (string, error) {
-	id := AskForStringValue("Link Id", "", true)
-	if linkTask.service.Username == "" {
-		linkTask.service.Username = AskForStringValue("Username", "", true)
-	}
-
-	if linkTask.service.Password == "" {
-		linkTask.service.Password = AskForStringValue("Password", "", true)
+func (linkTask *Task) DeleteLink(ctx context.Context, id string) (string, error) {
+	var err error
+	if id == "" {
+		if id, err = linkTask.prompter.String("Link Id", id, true); err != nil {
+			return id, err
+		}
 	}
 
-	if linkTask.service.ServiceURL == "" {
-		linkTask.service.ServiceURL = AskForStringValue("Service Url", "", true)
-	}
-
-	if linkTask.service.AuthKey == "" {
-		log.Fatal("AuthKey environment variable must be set.")
-	}
-
-	requestURL := "links/" + id
-
-	return id, linkTask.service.SendRequest("DELETE", requestURL, nil)
+	return id, linkTask.store.DeleteLink(ctx, id)
 }
@@ -0,0 +1,220 @@
+package tasks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+//Diagnostic is a problem lint found in an article file
+type Diagnostic struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (diagnostic Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s", diagnostic.Path, diagnostic.Line, diagnostic.Message)
+}
+
+//position is where a value was first seen, for reporting duplicates
+type position struct {
+	path string
+	line int
+}
+
+//linter checks the article files of a tree
+type linter struct {
+	task        *Task
+	diagnostics []Diagnostic
+	urls        map[string]position
+	ids         map[string]position
+}
+
+//Lint checks every article file under filedir without contacting the service
+func (articleTask *Task) Lint(filedir string) ([]Diagnostic, error) {
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	var err error
+	if filedir == "" {
+		if filedir, err = articleTask.prompter.String("Article Folder", filedir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &linter{
+		task: articleTask,
+		urls: make(map[string]position),
+		ids:  make(map[string]position),
+	}
+
+	subDirToSkip := []string{".git", ".DS_Store", stateDir}
+	err = filepath.Walk(filedir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && contains(subDirToSkip, info.Name()) {
+			return filepath.SkipDir
+		}
+
+		if !info.IsDir() && filepath.Ext(info.Name()) == ".md" {
+			l.lintFile(path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		if l.diagnostics[i].Path != l.diagnostics[j].Path {
+			return l.diagnostics[i].Path < l.diagnostics[j].Path
+		}
+
+		return l.diagnostics[i].Line < l.diagnostics[j].Line
+	})
+
+	return l.diagnostics, nil
+}
+
+func (l *linter) report(path string, line int, format string, a ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{path, line, fmt.Sprintf(format, a...)})
+}
+
+//lintFile checks the front matter of one article file
+func (l *linter) lintFile(path string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		l.report(path, 1, "could not open file: %s", err.Error())
+		return
+	}
+
+	header, _, start, err := splitFrontMatter(data)
+	if err != nil {
+		l.report(path, 1, "%s", err.Error())
+		return
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(header, &document); err != nil {
+		l.report(path, start, "invalid front matter: %s", err.Error())
+		return
+	}
+
+	fields := make(map[string]*yaml.Node)
+	if len(document.Content) > 0 && document.Content[0].Kind == yaml.MappingNode {
+		mapping := document.Content[0].Content
+		for i := 0; i+1 < len(mapping); i += 2 {
+			fields[mapping[i].Value] = mapping[i+1]
+		}
+	} else if len(document.Content) > 0 {
+		l.report(path, start, "front matter is not a mapping")
+		return
+	}
+
+	// node lines count from the start of the front matter
+	line := func(node *yaml.Node) int {
+		return start + node.Line - 1
+	}
+
+	required := []string{"title", "url"}
+	if l.task.settings.Defaults.Author == "" {
+		required = append(required, "author")
+	}
+
+	for _, name := range required {
+		node, ok := fields[name]
+		if !ok {
+			l.report(path, start, "missing %s", name)
+		} else if strings.TrimSpace(node.Value) == "" {
+			l.report(path, line(node), "%s is empty", name)
+		}
+	}
+
+	if node, ok := fields["publishDate"]; ok && node.Value != "" {
+		if _, err := parseDate(node.Value, l.task.location()); err != nil {
+			l.report(path, line(node), "publishDate: %s", err.Error())
+		}
+	}
+
+	if node, ok := fields["status"]; ok {
+		if _, err := parseStatus(node.Value); err != nil {
+			l.report(path, line(node), "%s", err.Error())
+		}
+	}
+
+	contentRoot := l.task.settings.ContentRoot
+	for _, image := range l.list(path, fields["images"], "images", line) {
+		if !fileExists(resolveImage(contentRoot, path, image.Value)) {
+			l.report(path, line(image), "image %s not found", image.Value)
+		}
+	}
+
+	if node, ok := fields["banner"]; ok && node.Value != "" {
+		if !fileExists(resolveImage(contentRoot, path, node.Value)) {
+			l.report(path, line(node), "banner %s not found", node.Value)
+		}
+	}
+
+	l.unique(path, fields["url"], "url", l.urls, line)
+	l.unique(path, fields["id"], "id", l.ids, line)
+
+	known := l.task.settings.Categories
+	for _, category := range l.list(path, fields["categories"], "categories", line) {
+		if len(known) > 0 && !containsFold(known, category.Value) {
+			l.report(path, line(category), "unknown category %s", category.Value)
+		}
+	}
+}
+
+//list returns the items of a list field, reporting a field that isn't a list
+func (l *linter) list(path string, node *yaml.Node, name string, line func(*yaml.Node) int) []*yaml.Node {
+	if node == nil || node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		l.report(path, line(node), "%s must be a list", name)
+		return nil
+	}
+
+	return node.Content
+}
+
+//unique reports a value that another file already uses
+func (l *linter) unique(path string, node *yaml.Node, name string, seen map[string]position, line func(*yaml.Node) int) {
+	if node == nil || strings.TrimSpace(node.Value) == "" {
+		return
+	}
+
+	if first, ok := seen[node.Value]; ok {
+		l.report(path, line(node), "duplicate %s %s, also in %s:%d", name, node.Value, first.path, first.line)
+		return
+	}
+
+	seen[node.Value] = position{path, line(node)}
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func containsFold(a []string, x string) bool {
+	for _, n := range a {
+		if strings.EqualFold(n, x) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package tasks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+//MemoryStore is an ArticleStore that keeps everything in memory, for tests and dry runs
+type MemoryStore struct {
+	lock     sync.Mutex
+	nextID   int
+	articles map[string]Article
+	links    map[string]Link
+	images   map[string]map[string][]byte
+}
+
+//NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		articles: make(map[string]Article),
+		links:    make(map[string]Link),
+		images:   make(map[string]map[string][]byte),
+	}
+}
+
+//ListArticles returns copies of all stored articles
+func (store *MemoryStore) ListArticles(ctx context.Context) ([]*Article, error) {
+	articles := store.Articles()
+
+	list := make([]*Article, len(articles))
+	for i := range articles {
+		list[i] = &articles[i]
+	}
+
+	return list, nil
+}
+
+//GetArticle returns a copy of the stored article
+func (store *MemoryStore) GetArticle(ctx context.Context, id string) (*Article, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	article, ok := store.articles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &article, nil
+}
+
+//CreateArticle stores the article under a new id
+func (store *MemoryStore) CreateArticle(ctx context.Context, article *Article) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	article.ID = store.newID()
+	store.articles[article.ID] = *article
+	return nil
+}
+
+//UpdateArticle replaces a stored article
+func (store *MemoryStore) UpdateArticle(ctx context.Context, article *Article) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if _, ok := store.articles[article.ID]; !ok {
+		return ErrNotFound
+	}
+
+	store.articles[article.ID] = *article
+	return nil
+}
+
+//DeleteArticle removes an article and its images
+func (store *MemoryStore) DeleteArticle(ctx context.Context, id string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if _, ok := store.articles[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(store.articles, id)
+	delete(store.images, id)
+	return nil
+}
+
+//SaveLink creates or replaces a link
+func (store *MemoryStore) SaveLink(ctx context.Context, link *Link) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if link.ID == "" {
+		link.ID = store.newID()
+	}
+
+	store.links[link.ID] = *link
+	return nil
+}
+
+//DeleteLink removes a link
+func (store *MemoryStore) DeleteLink(ctx context.Context, id string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if _, ok := store.links[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(store.links, id)
+	return nil
+}
+
+//UploadImage reads the image file and keeps its contents
+func (store *MemoryStore) UploadImage(ctx context.Context, articleID, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if store.images[articleID] == nil {
+		store.images[articleID] = make(map[string][]byte)
+	}
+
+	store.images[articleID][filepath.Base(path)] = data
+	return nil
+}
+
+//DownloadImage writes the stored image contents to w
+func (store *MemoryStore) DownloadImage(ctx context.Context, articleID, filename string, w io.Writer) error {
+	store.lock.Lock()
+	data, ok := store.images[articleID][filename]
+	store.lock.Unlock()
+
+	if !ok {
+		return ErrNotFound
+	}
+
+	_, err := w.Write(data)
+	return err
+}
+
+//ImageHash returns the hash of an image uploaded for the article
+func (store *MemoryStore) ImageHash(ctx context.Context, articleID, filename string) (string, bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	data, ok := store.images[articleID][filename]
+	if !ok {
+		return "", false, nil
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), true, nil
+}
+
+//ImageURL returns the path the image is kept under
+func (store *MemoryStore) ImageURL(articleID, filename string) string {
+	return "images/" + articleID + "/" + filename
+}
+
+//Articles returns copies of all stored articles ordered by id
+func (store *MemoryStore) Articles() []Article {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	articles := make([]Article, 0, len(store.articles))
+	for _, article := range store.articles {
+		articles = append(articles, article)
+	}
+
+	sort.Slice(articles, func(i, j int) bool {
+		return articles[i].ID < articles[j].ID
+	})
+
+	return articles
+}
+
+//Links returns copies of all stored links ordered by id
+func (store *MemoryStore) Links() []Link {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	links := make([]Link, 0, len(store.links))
+	for _, link := range store.links {
+		links = append(links, link)
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].ID < links[j].ID
+	})
+
+	return links
+}
+
+func (store *MemoryStore) newID() string {
+	store.nextID++
+	return fmt.Sprintf("%024x", store.nextID)
+}
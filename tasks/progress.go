@@ -0,0 +1,42 @@
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/evcraddock/article-importer/service"
+)
+
+//uploadProgressSize is the size of file from which upload progress is reported
+const uploadProgressSize = 1 << 20
+
+//uploadProgress returns a service.ProgressFunc printing the progress of large uploads to w
+//every ten percent. Uploads are told apart by path, as articles uploaded at once can have
+//images with the same name.
+func uploadProgress(w io.Writer) service.ProgressFunc {
+	var lock sync.Mutex
+	reported := make(map[string]int64)
+
+	return func(path string, sent, total int64) {
+		if total < uploadProgressSize {
+			return
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		step := sent * 10 / total
+		if step <= reported[path] {
+			return
+		}
+
+		reported[path] = step
+		if sent >= total {
+			delete(reported, path)
+		}
+
+		fmt.Fprintf(w, "uploading %s: %d%% (%.1f of %.1f MB) \n", filepath.Base(path), step*10, float64(sent)/(1<<20), float64(total)/(1<<20))
+	}
+}
@@ -0,0 +1,61 @@
+package tasks
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUploadProgress(t *testing.T) {
+	const total = 10 << 20
+
+	type call struct {
+		path string
+		sent int64
+	}
+
+	tests := []struct {
+		name  string
+		total int64
+		calls []call
+		want  []string
+	}{
+		{
+			name:  "small file",
+			total: 1 << 10,
+			calls: []call{{"a/cover.png", 1 << 10}},
+		},
+		{
+			name:  "every ten percent",
+			total: total,
+			calls: []call{{"a/cover.png", total / 20}, {"a/cover.png", total / 10}, {"a/cover.png", total / 8}, {"a/cover.png", total}},
+			want:  []string{"uploading cover.png: 10% (1.0 of 10.0 MB)", "uploading cover.png: 100% (10.0 of 10.0 MB)"},
+		},
+		{
+			name:  "same name in different articles",
+			total: total,
+			calls: []call{{"a/cover.png", total / 2}, {"b/cover.png", total / 10}, {"a/cover.png", total}, {"b/cover.png", total / 5}},
+			want: []string{"uploading cover.png: 50% (5.0 of 10.0 MB)", "uploading cover.png: 10% (1.0 of 10.0 MB)",
+				"uploading cover.png: 100% (10.0 of 10.0 MB)", "uploading cover.png: 20% (2.0 of 10.0 MB)"},
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		progress := uploadProgress(&out)
+		for _, c := range test.calls {
+			progress(c.path, c.sent, test.total)
+		}
+
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				got = append(got, line)
+			}
+		}
+
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s: uploadProgress printed %q, want %q", test.name, got, test.want)
+		}
+	}
+}
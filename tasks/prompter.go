@@ -0,0 +1,354 @@
+package tasks
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+	yaml "gopkg.in/yaml.v3"
+)
+
+//Prompter asks the user for the values a task needs
+type Prompter interface {
+	//String prompts for a string value
+	String(label string, defaultValue string, required bool) (string, error)
+	//Hidden prompts for a value which should not be displayed on the screen
+	Hidden(label string, defaultValue string, required bool) (string, error)
+	//CSV prompts for values seperated by commas
+	CSV(label string, defaultValue []string) ([]string, error)
+	//Date prompts for a date, which may be an expression like tomorrow 9am. Dates without an
+	//offset are in the location of the default value.
+	Date(label string, defaultValue time.Time) (time.Time, error)
+}
+
+//MissingValueError is returned when a required value can't be prompted for
+type MissingValueError struct {
+	Label string
+}
+
+func (err *MissingValueError) Error() string {
+	return fmt.Sprintf("missing value %s", err.Label)
+}
+
+//NewPrompter returns the prompter for the given input settings
+func NewPrompter(noInput bool, answersFile string) (Prompter, error) {
+	var prompter Prompter = NewTerminalPrompter()
+	if noInput {
+		prompter = &NonInteractivePrompter{}
+	}
+
+	if answersFile != "" {
+		return NewAnswersPrompter(answersFile, prompter)
+	}
+
+	return prompter, nil
+}
+
+//TerminalPrompter prompts the user on the terminal. It asks one question at a time, so tasks
+//running concurrently can share it.
+type TerminalPrompter struct {
+	reader *bufio.Reader
+	out    io.Writer
+	lock   sync.Mutex
+}
+
+//NewTerminalPrompter creates a TerminalPrompter reading from stdin
+func NewTerminalPrompter() *TerminalPrompter {
+	return &TerminalPrompter{
+		reader: bufio.NewReader(os.Stdin),
+		out:    os.Stdout,
+	}
+}
+
+//String prompts user for a string value
+func (prompter *TerminalPrompter) String(label string, defaultValue string, required bool) (string, error) {
+	prompter.lock.Lock()
+	defer prompter.lock.Unlock()
+
+	return prompter.readString(label, defaultValue, required)
+}
+
+//readString prompts for a string value, the caller holds the lock. At the end of the input
+//the default value is used.
+func (prompter *TerminalPrompter) readString(label string, defaultValue string, required bool) (string, error) {
+	for {
+		labelValue := label
+		if defaultValue != "" {
+			labelValue = label + " {" + defaultValue + "}"
+		}
+
+		if required {
+			requiredtext := "*"
+			labelValue = labelValue + " " + requiredtext
+		}
+
+		fmt.Fprintf(prompter.out, "%s : ", labelValue)
+
+		response, err := prompter.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+
+		value := strings.TrimRight(response, "\r\n")
+
+		if len(value) == 0 {
+			value = defaultValue
+		}
+
+		if required && strings.Trim(value, " ") == "" {
+			if err == io.EOF {
+				return "", &MissingValueError{label}
+			}
+
+			continue
+		}
+
+		return value, nil
+	}
+}
+
+//Hidden prompts the user for a value which should not be displayed on the screen
+func (prompter *TerminalPrompter) Hidden(label string, defaultValue string, required bool) (string, error) {
+	prompter.lock.Lock()
+	defer prompter.lock.Unlock()
+
+	if !terminal.IsTerminal(int(syscall.Stdin)) {
+		return prompter.readString(label, defaultValue, required)
+	}
+
+	for {
+		labelValue := label
+		if defaultValue != "" {
+			labelValue = label + " { ******** }"
+		}
+
+		if required {
+			requiredtext := "*"
+			labelValue = labelValue + " " + requiredtext
+		}
+
+		fmt.Fprintf(prompter.out, "%s : ", labelValue)
+		byteHidden, err := terminal.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintf(prompter.out, "\n")
+
+		if err != nil {
+			return "", err
+		}
+
+		hiddentext := string(byteHidden)
+		if hiddentext == "" {
+			hiddentext = defaultValue
+		}
+
+		if required && hiddentext == "" {
+			continue
+		}
+
+		return hiddentext, nil
+	}
+}
+
+//CSV prompts user for value seperated by commas
+func (prompter *TerminalPrompter) CSV(label string, defaultValue []string) ([]string, error) {
+	csvstring := removeWhiteSpace(strings.Join(defaultValue, ", "))
+
+	prompter.lock.Lock()
+	defer prompter.lock.Unlock()
+
+	newcsv, err := prompter.readString(label, csvstring, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCSV(newcsv), nil
+}
+
+//Date prompts user for a date
+func (prompter *TerminalPrompter) Date(label string, defaultValue time.Time) (time.Time, error) {
+	prompter.lock.Lock()
+	defer prompter.lock.Unlock()
+
+	for {
+		fmt.Fprintf(prompter.out, "%s {%s} : ", label, formatDate(defaultValue, defaultValue.Location()))
+
+		response, err := prompter.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return defaultValue, err
+		}
+
+		datestring := strings.TrimRight(response, "\r\n")
+		if len(datestring) == 0 {
+			return defaultValue, nil
+		}
+
+		dateValue, parseErr := parseDateExpression(datestring, time.Now(), defaultValue.Location())
+		if parseErr != nil {
+			if err == io.EOF {
+				return defaultValue, parseErr
+			}
+
+			fmt.Fprintf(prompter.out, "%s, please try again\n", parseErr.Error())
+			continue
+		}
+
+		if err == io.EOF {
+			return dateValue, nil
+		}
+
+		fmt.Fprintf(prompter.out, "%s is %s, is that right? {Y/n} : ", label, describeDate(dateValue, defaultValue.Location()))
+		confirm, err := prompter.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return defaultValue, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(confirm)) {
+		case "", "y", "yes":
+			return dateValue, nil
+		}
+	}
+}
+
+//NonInteractivePrompter never asks, it uses default values and fails when a required value has none
+type NonInteractivePrompter struct{}
+
+//String returns the default value
+func (prompter *NonInteractivePrompter) String(label string, defaultValue string, required bool) (string, error) {
+	if required && strings.Trim(defaultValue, " ") == "" {
+		return "", &MissingValueError{label}
+	}
+
+	return defaultValue, nil
+}
+
+//Hidden returns the default value
+func (prompter *NonInteractivePrompter) Hidden(label string, defaultValue string, required bool) (string, error) {
+	return prompter.String(label, defaultValue, required)
+}
+
+//CSV returns the default value
+func (prompter *NonInteractivePrompter) CSV(label string, defaultValue []string) ([]string, error) {
+	return defaultValue, nil
+}
+
+//Date returns the default value
+func (prompter *NonInteractivePrompter) Date(label string, defaultValue time.Time) (time.Time, error) {
+	return defaultValue, nil
+}
+
+//AnswersPrompter answers prompts from a YAML file of label to value,
+//asking the fallback prompter for any label that isn't in the file
+type AnswersPrompter struct {
+	answers  map[string]string
+	fallback Prompter
+}
+
+//NewAnswersPrompter loads an answers file
+func NewAnswersPrompter(fileName string, fallback Prompter) (*AnswersPrompter, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open answers file: %s", err.Error())
+	}
+
+	// answers are kept as they are written, so dates and numbers aren't reformatted
+	values := make(map[string]yaml.Node)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling answers file: %s", err.Error())
+	}
+
+	answers := make(map[string]string, len(values))
+	for label, value := range values {
+		switch {
+		case value.Kind == yaml.SequenceNode:
+			items := make([]string, len(value.Content))
+			for i, item := range value.Content {
+				items[i] = item.Value
+			}
+
+			answers[label] = strings.Join(items, ",")
+		case value.Tag == "!!null":
+			answers[label] = ""
+		default:
+			answers[label] = value.Value
+		}
+	}
+
+	return &AnswersPrompter{
+		answers:  answers,
+		fallback: fallback,
+	}, nil
+}
+
+//String returns the answer for the label
+func (prompter *AnswersPrompter) String(label string, defaultValue string, required bool) (string, error) {
+	value, ok := prompter.answers[label]
+	if !ok {
+		return prompter.fallback.String(label, defaultValue, required)
+	}
+
+	if value == "" {
+		value = defaultValue
+	}
+
+	if required && strings.Trim(value, " ") == "" {
+		return "", &MissingValueError{label}
+	}
+
+	return value, nil
+}
+
+//Hidden returns the answer for the label
+func (prompter *AnswersPrompter) Hidden(label string, defaultValue string, required bool) (string, error) {
+	if _, ok := prompter.answers[label]; !ok {
+		return prompter.fallback.Hidden(label, defaultValue, required)
+	}
+
+	return prompter.String(label, defaultValue, required)
+}
+
+//CSV returns the answer for the label
+func (prompter *AnswersPrompter) CSV(label string, defaultValue []string) ([]string, error) {
+	value, ok := prompter.answers[label]
+	if !ok {
+		return prompter.fallback.CSV(label, defaultValue)
+	}
+
+	return parseCSV(value), nil
+}
+
+//Date returns the answer for the label
+func (prompter *AnswersPrompter) Date(label string, defaultValue time.Time) (time.Time, error) {
+	value, ok := prompter.answers[label]
+	if !ok {
+		return prompter.fallback.Date(label, defaultValue)
+	}
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	dateValue, err := parseDateExpression(value, time.Now(), defaultValue.Location())
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s for %s", err.Error(), label)
+	}
+
+	return dateValue, nil
+}
+
+func parseCSV(value string) []string {
+	r := csv.NewReader(strings.NewReader(value))
+	stringArray, _ := r.Read()
+
+	for i, item := range stringArray {
+		stringArray[i] = strings.TrimSpace(item)
+	}
+
+	return stringArray
+}
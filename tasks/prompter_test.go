@@ -0,0 +1,40 @@
+package tasks
+
+import (
+	"bufio"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestTerminalPrompterString(t *testing.T) {
+	tests := []struct {
+		input        string
+		defaultValue string
+		required     bool
+		want         string
+		wantErr      bool
+	}{
+		{"value\n", "default", true, "value", false},
+		{"\n", "default", true, "default", false},
+		{"", "default", true, "default", false},
+		{"value", "", true, "value", false},
+		{"\n\nvalue\n", "", true, "value", false},
+		{"", "", false, "", false},
+		{"", "", true, "", true},
+		{"\n", "", true, "", true},
+	}
+
+	for _, test := range tests {
+		prompter := &TerminalPrompter{reader: bufio.NewReader(strings.NewReader(test.input)), out: ioutil.Discard}
+
+		got, err := prompter.String("Label", test.defaultValue, test.required)
+		if _, missing := err.(*MissingValueError); missing != test.wantErr || (err != nil && !missing) {
+			t.Errorf("String(%q) error = %v, want error %v", test.input, err, test.wantErr)
+		}
+
+		if got != test.want {
+			t.Errorf("String(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
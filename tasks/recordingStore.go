@@ -0,0 +1,213 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Operation is a change a dry run would have made
+type Operation struct {
+	Action  string
+	Target  string
+	Changes []FieldChange
+}
+
+//FieldChange is a field of an article an update would change
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+//dryRunIDPrefix marks the ids given to articles a dry run would have created
+const dryRunIDPrefix = "dry-run-"
+
+//RecordingStore is an ArticleStore that reads from another store but only records the writes
+type RecordingStore struct {
+	store ArticleStore
+
+	lock       sync.Mutex
+	nextID     int
+	operations []Operation
+}
+
+//NewRecordingStore creates a RecordingStore reading from store
+func NewRecordingStore(store ArticleStore) *RecordingStore {
+	return &RecordingStore{store: store}
+}
+
+//ListArticles returns the articles of the underlying store
+func (store *RecordingStore) ListArticles(ctx context.Context) ([]*Article, error) {
+	return store.store.ListArticles(ctx)
+}
+
+//GetArticle returns the article from the underlying store
+func (store *RecordingStore) GetArticle(ctx context.Context, id string) (*Article, error) {
+	if strings.HasPrefix(id, dryRunIDPrefix) {
+		return nil, ErrNotFound
+	}
+
+	return store.store.GetArticle(ctx, id)
+}
+
+//CreateArticle records the create and gives the article a placeholder id
+func (store *RecordingStore) CreateArticle(ctx context.Context, article *Article) error {
+	store.lock.Lock()
+	store.nextID++
+	article.ID = fmt.Sprintf("%s%d", dryRunIDPrefix, store.nextID)
+	store.lock.Unlock()
+
+	store.record(Operation{Action: "create article", Target: articleTarget(article)})
+	return nil
+}
+
+//UpdateArticle records the update with the fields that differ from the underlying store. An
+//article the dry run created has nothing to compare with, so only the update is recorded.
+func (store *RecordingStore) UpdateArticle(ctx context.Context, article *Article) error {
+	if strings.HasPrefix(article.ID, dryRunIDPrefix) {
+		store.record(Operation{Action: "update article", Target: articleTarget(article)})
+		return nil
+	}
+
+	current, err := store.GetArticle(ctx, article.ID)
+	if err != nil {
+		return err
+	}
+
+	store.record(Operation{
+		Action:  "update article",
+		Target:  articleTarget(article),
+		Changes: articleChanges(current, article),
+	})
+
+	return nil
+}
+
+//DeleteArticle records the delete
+func (store *RecordingStore) DeleteArticle(ctx context.Context, id string) error {
+	store.record(Operation{Action: "delete article", Target: id})
+	return nil
+}
+
+//SaveLink records the create or update of a link
+func (store *RecordingStore) SaveLink(ctx context.Context, link *Link) error {
+	action := "update link"
+	if link.ID == "" {
+		action = "create link"
+	}
+
+	store.record(Operation{Action: action, Target: fmt.Sprintf("%s (%s)", link.Title, link.URL)})
+	return nil
+}
+
+//DeleteLink records the delete
+func (store *RecordingStore) DeleteLink(ctx context.Context, id string) error {
+	store.record(Operation{Action: "delete link", Target: id})
+	return nil
+}
+
+//UploadImage records the upload
+func (store *RecordingStore) UploadImage(ctx context.Context, articleID, path string) error {
+	store.record(Operation{Action: "upload image", Target: fmt.Sprintf("%s to article %s", path, articleID)})
+	return nil
+}
+
+//DownloadImage reads the image from the underlying store
+func (store *RecordingStore) DownloadImage(ctx context.Context, articleID, filename string, w io.Writer) error {
+	return store.store.DownloadImage(ctx, articleID, filename, w)
+}
+
+//ImageHash checks the underlying store, articles the dry run created have no images
+func (store *RecordingStore) ImageHash(ctx context.Context, articleID, filename string) (string, bool, error) {
+	if strings.HasPrefix(articleID, dryRunIDPrefix) {
+		return "", false, nil
+	}
+
+	return store.store.ImageHash(ctx, articleID, filename)
+}
+
+//ImageURL returns the address from the underlying store
+func (store *RecordingStore) ImageURL(articleID, filename string) string {
+	return store.store.ImageURL(articleID, filename)
+}
+
+//Operations returns the recorded operations in the order they were made
+func (store *RecordingStore) Operations() []Operation {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return append([]Operation{}, store.operations...)
+}
+
+//WriteReport writes the recorded operations
+func (store *RecordingStore) WriteReport(w io.Writer) error {
+	operations := store.Operations()
+	if _, err := fmt.Fprintf(w, "dry run, %d operations not performed:\n", len(operations)); err != nil {
+		return err
+	}
+
+	for _, operation := range operations {
+		fmt.Fprintf(w, "  %s %s\n", operation.Action, operation.Target)
+		for _, change := range operation.Changes {
+			fmt.Fprintf(w, "      %s: %s -> %s\n", change.Field, change.From, change.To)
+		}
+	}
+
+	return nil
+}
+
+func (store *RecordingStore) record(operation Operation) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.operations = append(store.operations, operation)
+}
+
+func articleTarget(article *Article) string {
+	return fmt.Sprintf("%s (Id: %s)", article.Title, article.ID)
+}
+
+//articleChanges lists the fields which differ between two versions of an article
+func articleChanges(before, after *Article) []FieldChange {
+	var changes []FieldChange
+	compare := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{field, from, to})
+		}
+	}
+
+	compare("title", quote(before.Title), quote(after.Title))
+	compare("url", quote(before.URL), quote(after.URL))
+	compare("images", list(before.Images), list(after.Images))
+	compare("banner", quote(before.Banner), quote(after.Banner))
+	compare("publishDate", before.PublishDate.Format(time.RFC3339), after.PublishDate.Format(time.RFC3339))
+	compare("status", quote(before.Status), quote(after.Status))
+	compare("dataSource", quote(before.DataSource), quote(after.DataSource))
+	compare("author", quote(before.Author), quote(after.Author))
+	compare("categories", list(before.Categories), list(after.Categories))
+	compare("tags", list(before.Tags), list(after.Tags))
+
+	beforeExtra, _ := json.Marshal(before.Extra)
+	afterExtra, _ := json.Marshal(after.Extra)
+	compare("extra", string(beforeExtra), string(afterExtra))
+
+	// the content is too long to show, only say how it changed
+	if before.Content != after.Content {
+		changes = append(changes, FieldChange{"content", fmt.Sprintf("%d chars", len(before.Content)), fmt.Sprintf("%d chars", len(after.Content))})
+	}
+
+	return changes
+}
+
+func quote(value string) string {
+	return fmt.Sprintf("%q", value)
+}
+
+func list(values []string) string {
+	return "[" + strings.Join(values, ", ") + "]"
+}
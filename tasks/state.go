@@ -0,0 +1,251 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//stateDir is the folder in the article tree where local state is kept
+const stateDir = ".article-importer"
+
+//articleState records what an article looked like when it was last synced
+type articleState struct {
+	ID       string            `json:"id"`
+	Hash     string            `json:"hash"`
+	FileHash string            `json:"fileHash"`
+	Images   map[string]string `json:"images,omitempty"`
+	SyncedAt time.Time         `json:"syncedAt"`
+}
+
+//syncState is the local record of synced articles, keyed by path relative to the article tree
+type syncState struct {
+	fileName    string
+	root        string
+	contentRoot string
+	location    *time.Location
+	readOnly    bool
+	lock        sync.Mutex
+
+	Articles map[string]*articleState `json:"articles"`
+}
+
+//loadState reads the state file in the article tree, returning empty state when there is none
+func loadState(root string) (*syncState, error) {
+	state := &syncState{
+		fileName: filepath.Join(root, stateDir, "state.json"),
+		root:     root,
+		Articles: make(map[string]*articleState),
+	}
+
+	data, err := ioutil.ReadFile(state.fileName)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not open state file: %s", err.Error())
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling state file %s: %s", state.fileName, err.Error())
+	}
+
+	if state.Articles == nil {
+		state.Articles = make(map[string]*articleState)
+	}
+
+	return state, nil
+}
+
+//save writes the state file, unless the state is read only
+func (state *syncState) save() error {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	if state.readOnly {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(state.fileName), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(state.fileName, data, 0644)
+}
+
+//get returns the state recorded for a file
+func (state *syncState) get(path string) *articleState {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	return state.Articles[state.key(path)]
+}
+
+//record marks a file as synced with the given article, remembering the hashes of the file and its images
+func (state *syncState) record(path string, article *Article) {
+	fileHash, _ := fileHash(path)
+	images := state.imageHashes(path, article)
+
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	state.Articles[state.key(path)] = &articleState{
+		ID:       article.ID,
+		Hash:     articleHash(article, state.location),
+		FileHash: fileHash,
+		Images:   images,
+		SyncedAt: time.Now(),
+	}
+}
+
+//unchanged returns true when neither the file nor its images have changed since it was last synced
+func (state *syncState) unchanged(path string, article *Article) bool {
+	saved := state.get(path)
+	if saved == nil || saved.ID == "" || saved.ID != article.ID {
+		return false
+	}
+
+	current, err := fileHash(path)
+	if err != nil || current != saved.FileHash {
+		return false
+	}
+
+	images := state.imageHashes(path, article)
+	if len(images) != len(saved.Images) {
+		return false
+	}
+
+	for image, hash := range images {
+		if saved.Images[image] != hash {
+			return false
+		}
+	}
+
+	return true
+}
+
+//openState loads the state covering filedir, which a dry run doesn't write back
+func (task *Task) openState(filedir string) (*syncState, error) {
+	state, err := loadState(task.stateRoot(filedir))
+	if err != nil {
+		return nil, err
+	}
+
+	state.contentRoot = task.settings.ContentRoot
+	state.location = task.location()
+	state.readOnly = task.dryRun()
+	return state, nil
+}
+
+//stateRoot returns the folder whose state file covers filedir,
+//the content root when filedir is inside it and otherwise filedir itself
+func (task *Task) stateRoot(filedir string) string {
+	contentRoot := task.settings.ContentRoot
+	if contentRoot != "" {
+		rel, err := filepath.Rel(contentRoot, filedir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return contentRoot
+		}
+	}
+
+	if isdir, err := isDirectory(filedir); err == nil && !isdir {
+		return filepath.Dir(filedir)
+	}
+
+	return filedir
+}
+
+func (state *syncState) key(path string) string {
+	rel, err := filepath.Rel(state.root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+//articleHash hashes the fields of an article that are stored both locally and on the service
+func articleHash(article *Article, location *time.Location) string {
+	fields := struct {
+		Title       string
+		URL         string
+		Images      []string
+		Banner      string
+		PublishDate string
+		Status      string
+		Author      string
+		Categories  []string
+		Tags        []string
+		Content     string
+		Extra       map[string]interface{} `json:",omitempty"`
+	}{
+		article.Title,
+		article.URL,
+		nonNil(article.Images),
+		article.Banner,
+		hashDate(article.PublishDate, location),
+		article.Status,
+		article.Author,
+		nonNil(article.Categories),
+		nonNil(article.Tags),
+		strings.TrimSpace(article.Content),
+		article.Extra,
+	}
+
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+//fileHash returns the sha256 of a file's contents
+func fileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+//imageHashes returns the sha256 of each of the article's images that exist on disk
+func (state *syncState) imageHashes(path string, article *Article) map[string]string {
+	hashes := make(map[string]string)
+	for _, image := range article.Images {
+		hash, err := fileHash(resolveImage(state.contentRoot, path, image))
+		if err == nil {
+			hashes[image] = hash
+		}
+	}
+
+	return hashes
+}
+
+//nonNil makes nil and empty lists hash the same way
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
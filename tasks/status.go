@@ -0,0 +1,82 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//Article statuses
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+//parseStatus checks a status from front matter, articles without one are published
+func parseStatus(status string) (string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case "":
+		return StatusPublished, nil
+	case StatusDraft, StatusScheduled, StatusPublished, StatusArchived:
+		return status, nil
+	}
+
+	return "", fmt.Errorf("Invalid status %s, must be one of %s, %s, %s or %s", status, StatusDraft, StatusScheduled, StatusPublished, StatusArchived)
+}
+
+//PublishDue publishes the scheduled articles under filedir whose publish date has passed
+func (articleTask *Task) PublishDue(ctx context.Context, filedir string, now time.Time) ([]*Article, error) {
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	var err error
+	if filedir == "" {
+		if filedir, err = articleTask.prompter.String("Article Folder", filedir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := articleTask.openState(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	locals, failed := articleTask.readArticleTree(filedir)
+	for _, result := range failed {
+		articleTask.printf("skipping %s: %s \n", result.Path, result.Reason)
+	}
+
+	var published []*Article
+	for _, local := range locals {
+		article := local.article
+		if article.Status != StatusScheduled || article.PublishDate.After(now) {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		articleTask.printf("publishing scheduled article: %s \n", local.path)
+
+		article.Status = StatusPublished
+		if _, err := articleTask.SaveArticle(ctx, article, true); err != nil {
+			state.save()
+			return published, fmt.Errorf("%s: %w", local.path, err)
+		}
+
+		state.record(local.path, article)
+		published = append(published, article)
+	}
+
+	if err := state.save(); err != nil {
+		return published, err
+	}
+
+	return published, ctx.Err()
+}
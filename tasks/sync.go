@@ -0,0 +1,290 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//SyncAction is what sync did, or would do, with an article
+type SyncAction string
+
+//Sync actions
+const (
+	SyncUnchanged SyncAction = "unchanged"
+	SyncPush      SyncAction = "push"
+	SyncPull      SyncAction = "pull"
+	SyncConflict  SyncAction = "conflict"
+	SyncSkipped   SyncAction = "skipped"
+	SyncFailed    SyncAction = "failed"
+)
+
+//SyncOptions controls which way sync copies articles
+type SyncOptions struct {
+	PushOnly bool
+	PullOnly bool
+	//IncludeDrafts syncs draft articles too
+	IncludeDrafts bool
+}
+
+//SyncResult is the outcome of syncing one article
+type SyncResult struct {
+	Path   string
+	ID     string
+	Title  string
+	Action SyncAction
+	Reason string
+}
+
+//localArticle is an article read from the local tree
+type localArticle struct {
+	path    string
+	article *Article
+}
+
+//Sync compares the local article tree with the articles on the service, pushing local changes,
+//pulling remote changes and reporting conflicts where both sides changed since the last sync
+func (articleTask *Task) Sync(ctx context.Context, filedir string, options SyncOptions) ([]SyncResult, error) {
+	if options.PushOnly && options.PullOnly {
+		return nil, fmt.Errorf("Only one of push only and pull only can be used")
+	}
+
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	var err error
+	if filedir == "" {
+		if filedir, err = articleTask.prompter.String("Article Folder", filedir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := articleTask.openState(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SyncResult
+	locals, failed := articleTask.readArticleTree(filedir)
+	results = append(results, failed...)
+
+	remotes, err := articleTask.store.ListArticles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteByID := make(map[string]*Article, len(remotes))
+	for _, remote := range remotes {
+		remoteByID[remote.ID] = remote
+	}
+
+	localIDs := make(map[string]bool)
+	localPaths := make(map[string]bool)
+	for _, local := range locals {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		localPaths[local.path] = true
+		if local.article.ID != "" {
+			localIDs[local.article.ID] = true
+		}
+
+		result := articleTask.syncLocal(ctx, state, local, remoteByID[local.article.ID], options)
+		results = append(results, result)
+	}
+
+	for _, remote := range remotes {
+		if localIDs[remote.ID] {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		path := articleFilePath(filedir, remote)
+		result := SyncResult{Path: path, ID: remote.ID, Title: remote.Title, Action: SyncPull}
+
+		if _, err := os.Stat(path); err == nil || localPaths[path] {
+			result.Action = SyncConflict
+			result.Reason = "only on the service but the local file already exists"
+		} else if options.PushOnly {
+			result.Action = SyncSkipped
+			result.Reason = "only on the service"
+		} else {
+			result = articleTask.pullArticle(ctx, state, path, remote, result)
+		}
+
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	if err := state.save(); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+//syncLocal decides what to do with a local article that may also be on the service
+func (articleTask *Task) syncLocal(ctx context.Context, state *syncState, local localArticle, remote *Article, options SyncOptions) SyncResult {
+	article := local.article
+	result := SyncResult{Path: local.path, ID: article.ID, Title: article.Title}
+
+	baseHash := ""
+	if saved := state.get(local.path); saved != nil && saved.ID == article.ID {
+		baseHash = saved.Hash
+	}
+
+	if article.Status == StatusDraft && !options.IncludeDrafts {
+		result.Action = SyncSkipped
+		result.Reason = "draft"
+		return result
+	}
+
+	localHash := articleHash(article, state.location)
+
+	switch {
+	case article.ID == "":
+		result.Action = SyncPush
+		result.Reason = "new article"
+	case remote == nil && baseHash != "":
+		result.Action = SyncConflict
+		result.Reason = "deleted on the service"
+	case remote == nil:
+		result.Action = SyncPush
+		result.Reason = "not on the service"
+	default:
+		remoteHash := articleHash(remote, state.location)
+		localChanged := localHash != baseHash
+		remoteChanged := remoteHash != baseHash
+
+		switch {
+		case localHash == remoteHash:
+			result.Action = SyncUnchanged
+		case baseHash == "":
+			result.Action = SyncConflict
+			result.Reason = "differs from the service and has never been synced"
+		case localChanged && remoteChanged:
+			result.Action = SyncConflict
+			result.Reason = "changed locally and on the service"
+		case localChanged:
+			result.Action = SyncPush
+			result.Reason = "changed locally"
+		default:
+			result.Action = SyncPull
+			result.Reason = "changed on the service"
+		}
+	}
+
+	if (result.Action == SyncPush && options.PullOnly) || (result.Action == SyncPull && options.PushOnly) {
+		result.Reason = result.Reason + ", not copied"
+		result.Action = SyncSkipped
+	}
+
+	switch result.Action {
+	case SyncUnchanged:
+		state.record(local.path, article)
+	case SyncPush:
+		saved, err := articleTask.SaveArticle(ctx, article, true)
+		if err != nil {
+			result.Action = SyncFailed
+			result.Reason = err.Error()
+			return result
+		}
+
+		result.ID = saved.ID
+		state.record(local.path, saved)
+	case SyncPull:
+		return articleTask.pullArticle(ctx, state, local.path, remote, result)
+	}
+
+	return result
+}
+
+//pullArticle writes a remote article to a local markdown file and downloads its images next to it
+func (articleTask *Task) pullArticle(ctx context.Context, state *syncState, path string, remote *Article, result SyncResult) SyncResult {
+	pulled := *remote
+	pulled.DataSource = articleTask.dataSource(path)
+
+	if !articleTask.dryRun() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			result.Action = SyncFailed
+			result.Reason = err.Error()
+			return result
+		}
+	}
+
+	if err := articleTask.downloadImages(ctx, filepath.Dir(path), &pulled); err != nil {
+		result.Action = SyncFailed
+		result.Reason = err.Error()
+		return result
+	}
+
+	if err := articleTask.saveMarkdownFile(pulled); err != nil {
+		result.Action = SyncFailed
+		result.Reason = err.Error()
+		return result
+	}
+
+	state.record(path, &pulled)
+	return result
+}
+
+//readArticleTree reads every article file under filedir
+func (articleTask *Task) readArticleTree(filedir string) ([]localArticle, []SyncResult) {
+	var locals []localArticle
+	var failed []SyncResult
+
+	subDirToSkip := []string{".git", ".DS_Store", stateDir}
+	err := filepath.Walk(filedir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && contains(subDirToSkip, info.Name()) {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || filepath.Ext(info.Name()) != ".md" {
+			return nil
+		}
+
+		article, err := articleTask.readArticleFile(path)
+		if err != nil {
+			failed = append(failed, SyncResult{Path: path, Action: SyncFailed, Reason: err.Error()})
+			return nil
+		}
+
+		locals = append(locals, localArticle{path, article})
+		return nil
+	})
+
+	if err != nil {
+		failed = append(failed, SyncResult{Path: filedir, Action: SyncFailed, Reason: err.Error()})
+	}
+
+	return locals, failed
+}
+
+//articleFilePath returns where an article is kept in the tree, following the folder per article layout of ImportArticle
+func articleFilePath(filedir string, article *Article) string {
+	slug := ""
+	if strings.Trim(article.URL, "/") != "" {
+		slug = strings.TrimSuffix(GetFileName(article.URL, "/"), ".md")
+	}
+
+	if slug == "" {
+		slug = article.ID
+	}
+
+	return filepath.Join(filedir, slug, slug+".md")
+}
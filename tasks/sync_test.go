@@ -0,0 +1,164 @@
+package tasks
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evcraddock/article-importer/config"
+)
+
+func TestSyncPull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "articles-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	store := NewMemoryStore()
+	article := &Article{
+		Title:       "Remote",
+		URL:         "remote.md",
+		PublishDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Status:      StatusPublished,
+		Author:      "Erik",
+		Banner:      "banner.png",
+		Images:      []string{"img/a.png"},
+	}
+
+	if err := store.CreateArticle(context.Background(), article); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{"a.png": "a", "banner.png": "banner"} {
+		upload := filepath.Join(dir, name)
+		writeFile(t, upload, content)
+		if err := store.UploadImage(context.Background(), article.ID, upload); err != nil {
+			t.Fatal(err)
+		}
+
+		os.Remove(upload)
+	}
+
+	task := NewTaskWithStore(&config.Settings{ContentRoot: dir}, store, &NonInteractivePrompter{})
+	task.out = ioutil.Discard
+
+	results, err := task.Sync(context.Background(), dir, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Sync failed: %s", err)
+	}
+
+	if len(results) != 1 || results[0].Action != SyncPull {
+		t.Fatalf("Sync results = %+v, want one pull", results)
+	}
+
+	tests := []struct {
+		path    string
+		content string
+	}{
+		{"remote/img/a.png", "a"},
+		{"remote/banner.png", "banner"},
+	}
+
+	for _, test := range tests {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(test.path)))
+		if err != nil || string(data) != test.content {
+			t.Errorf("Sync wrote %s = %q, %v, want %q", test.path, data, err, test.content)
+		}
+	}
+
+	if !fileExists(filepath.Join(dir, "remote", "remote.md")) {
+		t.Errorf("Sync didn't write the article file")
+	}
+}
+
+func TestSyncDrafts(t *testing.T) {
+	tests := []struct {
+		includeDrafts bool
+		want          SyncAction
+		wantSaved     int
+	}{
+		{false, SyncSkipped, 0},
+		{true, SyncPush, 1},
+	}
+
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "articles-")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer os.RemoveAll(dir)
+
+		writeFile(t, filepath.Join(dir, "draft", "draft.md"),
+			"---\ntitle: Draft\nurl: draft.md\npublishDate: 2026-10-18\nstatus: draft\ndataSource: draft/draft.md\nauthor: Erik\n---\nNot yet\n")
+
+		store := NewMemoryStore()
+		task := NewTaskWithStore(&config.Settings{ContentRoot: dir}, store, &NonInteractivePrompter{})
+		task.out = ioutil.Discard
+
+		results, err := task.Sync(context.Background(), dir, SyncOptions{IncludeDrafts: test.includeDrafts})
+		if err != nil {
+			t.Fatalf("Sync failed: %s", err)
+		}
+
+		if len(results) != 1 || results[0].Action != test.want {
+			t.Errorf("Sync with drafts %v = %+v, want %s", test.includeDrafts, results, test.want)
+		}
+
+		if saved := len(store.Articles()); saved != test.wantSaved {
+			t.Errorf("Sync with drafts %v saved %d articles, want %d", test.includeDrafts, saved, test.wantSaved)
+		}
+	}
+}
+
+func TestSyncDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "articles-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	writeFile(t, filepath.Join(dir, "local", "local.md"),
+		"---\ntitle: Local\nurl: local.md\npublishDate: 2026-10-18\ndataSource: local/local.md\nauthor: Erik\n---\nNew\n")
+
+	store := NewMemoryStore()
+	remote := &Article{Title: "Remote", URL: "remote.md", Author: "Erik", Status: StatusPublished}
+	if err := store.CreateArticle(context.Background(), remote); err != nil {
+		t.Fatal(err)
+	}
+
+	task := NewTaskWithStore(&config.Settings{ContentRoot: dir, DryRun: true}, store, &NonInteractivePrompter{})
+	task.out = ioutil.Discard
+
+	results, err := task.Sync(context.Background(), dir, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Sync failed: %s", err)
+	}
+
+	var actions []SyncAction
+	for _, result := range results {
+		actions = append(actions, result.Action)
+	}
+
+	if len(actions) != 2 || actions[0] != SyncPush || actions[1] != SyncPull {
+		t.Errorf("Sync actions = %v, want push and pull", actions)
+	}
+
+	if saved := len(store.Articles()); saved != 1 {
+		t.Errorf("Sync stored %d articles in a dry run, want 1", saved)
+	}
+
+	if fileExists(filepath.Join(dir, "remote")) || fileExists(filepath.Join(dir, stateDir)) {
+		t.Errorf("Sync wrote files in a dry run")
+	}
+
+	if len(task.recorder.Operations()) == 0 {
+		t.Errorf("Sync recorded no operations")
+	}
+}
@@ -1,137 +1,88 @@
 package tasks
 
 import (
-	"bufio"
 	"encoding/csv"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"strings"
-	"syscall"
 	"time"
 	"unicode"
 
 	"github.com/evcraddock/article-importer/config"
 	"github.com/evcraddock/article-importer/service"
-	"golang.org/x/crypto/ssh/terminal"
 )
 
 //Task stores task information
 type Task struct {
-	service *service.HTTPService
+	settings  *config.Settings
+	store     ArticleStore
+	prompter  Prompter
+	out       io.Writer
+	uploads   chan struct{}
+	images    *imageCache
+	recorder  *RecordingStore
+	publishAt time.Time
 }
 
-//NewTask creates new instance of a Task
-func NewTask(settings *config.Settings) *Task {
-	service := service.NewHTTPService(settings.Auth)
-
-	task := &Task{
-		service,
+//NewTask creates new instance of a Task that publishes to the article service
+func NewTask(settings *config.Settings) (*Task, error) {
+	prompter, err := NewPrompter(settings.NoInput, settings.AnswersFile)
+	if err != nil {
+		return nil, err
 	}
 
-	return task
-}
-
-//AskForStringValue prompts user for a string value
-func AskForStringValue(label string, defaultValue string, required bool) string {
-	reader := bufio.NewReader(os.Stdin)
+	service := service.NewHTTPService(settings)
+	service.UploadProgress = uploadProgress(os.Stderr)
 
-	for {
-		labelValue := label
-		if defaultValue != "" {
-			labelValue = label + " {" + defaultValue + "}"
-		}
-
-		if required {
-			requiredtext := "*"
-			labelValue = labelValue + " " + requiredtext
-		}
-
-		fmt.Printf("%s : ", labelValue)
-
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		value := strings.Replace(response, "\n", "", -1)
-
-		if len(value) == 0 {
-			value = defaultValue
-		}
-
-		if required && strings.Trim(value, " ") == "" {
-			fmt.Printf("")
-			continue
-		}
-
-		return value
-	}
+	return NewTaskWithStore(settings, newHTTPStore(service, settings, prompter), prompter), nil
 }
 
-//AskForHiddenStringValue prompts the user for a value which should not be displayed on the screen
-func AskForHiddenStringValue(label string, defaultValue string, required bool) string {
-	for {
-		labelValue := label
-		if defaultValue != "" {
-			labelValue = label + " { ******** }"
-		}
-
-		if required {
-			requiredtext := "*"
-			labelValue = labelValue + " " + requiredtext
-		}
-
-		fmt.Printf("%s : ", labelValue)
-		byteHidden, err := terminal.ReadPassword(int(syscall.Stdin))
-		fmt.Printf("\n")
-
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		hiddentext := string(byteHidden)
-		return hiddentext
+//NewTaskWithStore creates new instance of a Task that publishes to the given store. In a dry
+//run the store is only read from and the writes are recorded instead.
+func NewTaskWithStore(settings *config.Settings, store ArticleStore, prompter Prompter) *Task {
+	task := &Task{
+		settings: settings,
+		store:    store,
+		prompter: prompter,
+		out:      os.Stdout,
+		images:   loadImageCache(settings.Images.CacheFile),
 	}
-}
-
-//AskForCSV prompts user for value seperated by commas
-func AskForCSV(label string, defaultValue []string) []string {
-	csvstring := removeWhiteSpace(strings.Join(defaultValue, ", "))
 
-	newcsv := AskForStringValue(label, csvstring, false)
+	if settings.DryRun {
+		task.recorder = NewRecordingStore(store)
+		task.store = task.recorder
+	}
 
-	r := csv.NewReader(strings.NewReader(newcsv))
-	stringArray, _ := r.Read()
-	return stringArray
+	return task
 }
 
-//AskForDateValue prompts user for a date
-func AskForDateValue(label string, defaultValue time.Time) time.Time {
-	reader := bufio.NewReader(os.Stdin)
-	dateValue := defaultValue
+//PrintDryRun prints the operations a dry run skipped, it does nothing outside a dry run
+func (task *Task) PrintDryRun() error {
+	if task.recorder == nil {
+		return nil
+	}
 
-	for {
-		fmt.Printf("%s {%d/%d/%d} : ", label, defaultValue.Month(), defaultValue.Day(), defaultValue.Year())
+	return task.recorder.WriteReport(task.out)
+}
 
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			log.Fatal(err)
-		}
+func (task *Task) dryRun() bool {
+	return task.recorder != nil
+}
 
-		datestring := strings.Replace(response, "\n", "", -1)
-		if len(datestring) == 0 {
-			return defaultValue
-		}
+//recordFile notes a change to a local file a dry run skipped
+func (task *Task) recordFile(action, path string) {
+	task.recorder.record(Operation{Action: action, Target: path})
+}
 
-		dateValue, err = time.Parse("01/02/2006", datestring)
-		if err != nil {
-			fmt.Printf("Invalid Date, please try again {dd/mm/yyyy}\n")
-			continue
-		}
+//SetOutput changes where progress output is written
+func (task *Task) SetOutput(w io.Writer) {
+	task.out = w
+}
 
-		return dateValue
-	}
+//printf writes progress output for the task
+func (task *Task) printf(format string, a ...interface{}) {
+	fmt.Fprintf(task.out, format, a...)
 }
 
 //GetFileName returns the filename from a path
@@ -153,7 +104,11 @@ func getStringArray(value string) ([]string, error) {
 
 func isDirectory(path string) (bool, error) {
 	fileInfo, err := os.Stat(path)
-	return fileInfo.IsDir(), err
+	if err != nil {
+		return false, err
+	}
+
+	return fileInfo.IsDir(), nil
 }
 
 func removeWhiteSpace(str string) string {
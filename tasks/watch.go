@@ -0,0 +1,209 @@
+package tasks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+//WatchOptions controls how the article tree is watched
+type WatchOptions struct {
+	//Debounce is how long to wait after the last change to a file before publishing it
+	Debounce time.Duration
+	//IncludeDrafts publishes draft articles too
+	IncludeDrafts bool
+}
+
+//watcher tracks the article tree while watching it
+type watcher struct {
+	task    *Task
+	fs      *fsnotify.Watcher
+	options WatchOptions
+
+	//images maps an image path to the article files that reference it
+	images map[string][]string
+	//published is the hash of each article file as it was after it was last read or written,
+	//so the rewrite saveMarkdownFile does when publishing isn't published again
+	published map[string]string
+}
+
+//Watch republishes article files under filedir when they, or the images they reference, change
+func (articleTask *Task) Watch(ctx context.Context, filedir string, options WatchOptions) error {
+	if filedir == "" {
+		filedir = articleTask.settings.ContentRoot
+	}
+
+	var err error
+	if filedir == "" {
+		if filedir, err = articleTask.prompter.String("Article Folder", filedir, true); err != nil {
+			return err
+		}
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	defer fsWatcher.Close()
+
+	w := &watcher{
+		task:      articleTask,
+		fs:        fsWatcher,
+		options:   options,
+		images:    make(map[string][]string),
+		published: make(map[string]string),
+	}
+
+	if err := w.addTree(filedir); err != nil {
+		return err
+	}
+
+	articleTask.printf("watching %s for changes \n", filedir)
+
+	// changed article files, true when only a referenced image changed
+	pending := make(map[string]bool)
+	timer := time.NewTimer(options.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-fsWatcher.Errors:
+			articleTask.printf("watch error: %s \n", err.Error())
+		case event := <-fsWatcher.Events:
+			if w.changed(event, pending) {
+				timer.Reset(options.Debounce)
+			}
+		case <-timer.C:
+			w.publish(ctx, pending)
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+//addTree watches every folder under root and indexes the article files in it
+func (w *watcher) addTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" || info.Name() == stateDir {
+				return filepath.SkipDir
+			}
+
+			return w.fs.Add(path)
+		}
+
+		if filepath.Ext(path) == ".md" {
+			w.index(path)
+		}
+
+		return nil
+	})
+}
+
+//index records the hash of an article file and the images it references
+func (w *watcher) index(path string) {
+	if hash, err := fileHash(path); err == nil {
+		w.published[path] = hash
+	}
+
+	article, err := w.task.readArticleFile(path)
+	if err != nil {
+		return
+	}
+
+	for _, image := range article.Images {
+		imagePath := resolveImage(w.task.settings.ContentRoot, path, image)
+		if !contains(w.images[imagePath], path) {
+			w.images[imagePath] = append(w.images[imagePath], path)
+		}
+	}
+}
+
+//changed adds the article files affected by an event to pending, returning whether there were any
+func (w *watcher) changed(event fsnotify.Event, pending map[string]bool) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+		return false
+	}
+
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.addTree(event.Name); err != nil {
+				w.task.printf("watch error: %s \n", err.Error())
+			}
+
+			return false
+		}
+	}
+
+	if filepath.Ext(event.Name) == ".md" {
+		if _, ok := pending[event.Name]; !ok {
+			pending[event.Name] = false
+		}
+
+		return true
+	}
+
+	articles := w.images[event.Name]
+	for _, path := range articles {
+		pending[path] = true
+	}
+
+	return len(articles) > 0
+}
+
+//publish loads each pending article file whose contents changed since it was last published
+func (w *watcher) publish(ctx context.Context, pending map[string]bool) {
+	paths := make([]string, 0, len(pending))
+	for path := range pending {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		hash, err := fileHash(path)
+		if err != nil {
+			// removed or renamed away before the debounce ran out
+			continue
+		}
+
+		if !pending[path] && hash == w.published[path] {
+			continue
+		}
+
+		if existing, err := w.task.readArticleFile(path); err == nil && existing.Status == StatusDraft && !w.options.IncludeDrafts {
+			w.task.printf("skipping draft: %s \n", path)
+			w.published[path] = hash
+			w.index(path)
+			continue
+		}
+
+		w.task.printf("publishing changed file: %s \n", path)
+
+		article, err := w.task.LoadArticle(ctx, path, true)
+		if err != nil {
+			w.task.printf("error: %s \n", err.Error())
+			w.published[path] = hash
+			continue
+		}
+
+		w.task.printf("published %s (Id: %s) \n", article.Title, article.ID)
+
+		w.index(path)
+		if written := w.task.localPath(article.DataSource); written != path {
+			if hash, err := fileHash(written); err == nil {
+				w.published[written] = hash
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evcraddock/article-importer/config"
+)
+
+func TestWatchPublish(t *testing.T) {
+	tests := []struct {
+		status        string
+		includeDrafts bool
+		wantSaved     int
+	}{
+		{StatusPublished, false, 1},
+		{StatusDraft, false, 0},
+		{StatusDraft, true, 1},
+	}
+
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "articles-")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "post", "post.md")
+		writeFile(t, path, "---\ntitle: Post\nurl: post.md\npublishDate: 2026-10-18\nstatus: "+test.status+
+			"\ndataSource: post/post.md\nauthor: Erik\n---\nChanged\n")
+
+		store := NewMemoryStore()
+		task := NewTaskWithStore(&config.Settings{ContentRoot: dir}, store, &NonInteractivePrompter{})
+		task.out = ioutil.Discard
+
+		w := &watcher{
+			task:      task,
+			options:   WatchOptions{IncludeDrafts: test.includeDrafts},
+			images:    make(map[string][]string),
+			published: make(map[string]string),
+		}
+
+		w.publish(context.Background(), map[string]bool{path: false})
+
+		if saved := len(store.Articles()); saved != test.wantSaved {
+			t.Errorf("publish %s with drafts %v saved %d articles, want %d", test.status, test.includeDrafts, saved, test.wantSaved)
+		}
+
+		// the file isn't published again until it changes
+		w.publish(context.Background(), map[string]bool{path: false})
+		if saved := len(store.Articles()); saved != test.wantSaved {
+			t.Errorf("publish %s again saved %d articles, want %d", test.status, saved, test.wantSaved)
+		}
+	}
+}